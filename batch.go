@@ -0,0 +1,385 @@
+package namedParameterQuery
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+/*
+	BatchInsert turns a single-row INSERT into multi-row INSERTs. Given a query such as:
+
+		INSERT INTO users (id, name) VALUES (:id, :name)
+
+	binding a slice of three rows gives the query:
+
+		INSERT INTO users (id, name) VALUES (?, ?), (?, ?), (?, ?)
+
+	with every row's values in order. Each row may be anything SetValues accepts, e.g. a struct or a map.
+
+	Since databases limit how many parameters a single query may have, the rows are split into as many batches
+	as the dialect needs (see Dialect.MaxParameters). Parameters outside the VALUES tuple,
+	e.g. in an "ON CONFLICT" clause, are written once per batch, and take their values from the first row of each batch.
+	Slice values inside the tuple are expanded as usual, but aren't counted towards the limit.
+
+	Like a QueryTemplate, a BatchInsert never changes once created, so it is safe to share between goroutines.
+*/
+type BatchInsert struct {
+
+	// The single-row query, which each row is bound to.
+	template *QueryTemplate
+
+	// The template's segments before, inside, and after the VALUES tuple.
+	prefix []segment
+	tuple []segment
+	suffix []segment
+
+	// The (mapped) names of parameters which appear inside, and outside, the VALUES tuple.
+	tupleNames map[string]bool
+	outsideNames map[string]bool
+
+	// The most rows which fit in one batch.
+	rowsPerBatch int
+
+	// The template for a full batch, built the first time it's needed.
+	fullBatch *QueryTemplate
+	fullBatchOnce sync.Once
+}
+
+/*
+	NewBatchInsert parses the given [queryText] according to the given [options], and finds its VALUES tuple.
+	A malformed query is reported as a *ParseError, and a query with no VALUES tuple is also an error.
+*/
+func NewBatchInsert(queryText string, options QueryOptions) (*BatchInsert, error) {
+
+	var ret *BatchInsert
+	var start, end int
+	var found bool
+
+	template, err := ParseQueryTemplate(queryText, options)
+	if(err != nil) {
+		return nil, err
+	}
+
+	start, end, found = findValuesTuple(template)
+	if(!found) {
+		return nil, errors.New("Unable to create batch insert: query has no VALUES tuple")
+	}
+
+	ret = new(BatchInsert)
+	ret.template = template
+	ret.prefix = sliceSegments(template.segments, 0, start)
+	ret.tuple = sliceSegments(template.segments, start, end)
+	ret.suffix = sliceSegments(template.segments, end, len(queryText))
+	ret.tupleNames = segmentNames(ret.tuple)
+	ret.outsideNames = segmentNames(append(append([]segment(nil), ret.prefix...), ret.suffix...))
+	ret.rowsPerBatch = ret.fitRows()
+	return ret, nil
+}
+
+/*
+	RowsPerBatch returns the most rows which will be put into a single batch.
+*/
+func (this *BatchInsert) RowsPerBatch() (int) {
+	return this.rowsPerBatch
+}
+
+/*
+	Bind binds every row of the given [rows], which must be a slice or array (or a pointer to one),
+	returning one Binding per batch of rows. Each Binding's GetParsedQuery and GetParsedParameters
+	(or Args, to check that every parameter was given a value) can be executed as-is.
+	If [rows] is empty, no bindings are returned.
+*/
+func (this *BatchInsert) Bind(rows interface{}) ([]*Binding, error) {
+
+	var ret []*Binding
+	var binding *Binding
+	var end int
+
+	values, found := indirect(reflect.ValueOf(rows))
+	if(!found || (values.Kind() != reflect.Slice && values.Kind() != reflect.Array)) {
+		return nil, errors.New("Unable to bind batch insert: rows are not a slice")
+	}
+
+	for start := 0; start < values.Len(); start += this.rowsPerBatch {
+
+		end = minimum(start + this.rowsPerBatch, values.Len())
+
+		if(end - start == this.rowsPerBatch) {
+			binding = this.fullTemplate().Bind()
+		} else {
+			binding = this.batchTemplate(end - start).Bind()
+		}
+
+		for row := start; row < end; row++ {
+
+			err := this.bindRow(binding, row - start, values.Index(row).Interface())
+			if(err != nil) {
+				return nil, err
+			}
+		}
+
+		ret = append(ret, binding)
+	}
+	return ret, nil
+}
+
+/*
+	bindRow sets the values of the given [row] of the given batch [binding] from the given [parameters].
+*/
+func (this *BatchInsert) bindRow(binding *Binding, row int, parameters interface{}) (error) {
+
+	rowBinding := this.template.Bind()
+
+	err := rowBinding.SetValues(parameters)
+	if(err != nil) {
+		return err
+	}
+
+	for _, name := range this.template.names {
+
+		value, found := rowBinding.value(name)
+		if(!found) {
+			continue
+		}
+
+		if(this.tupleNames[name]) {
+			binding.setValue(rowParameterName(name, row), value)
+		}
+		if(row == 0 && this.outsideNames[name]) {
+			binding.setValue(name, value)
+		}
+	}
+	return nil
+}
+
+/*
+	fitRows returns how many rows fit in one batch without going over the dialect's parameter limit.
+	At least one row always fits, even if that one row goes over the limit.
+*/
+func (this *BatchInsert) fitRows() (int) {
+
+	var limit int
+	var perRow int
+	var rows int
+
+	limit = this.template.options.Dialect.MaxParameters()
+	perRow = this.countPlaceholders(this.tuple)
+
+	if(limit <= 0 || perRow == 0) {
+		return int(^uint(0) >> 1)
+	}
+
+	rows = (limit - this.countPlaceholders(this.prefix) - this.countPlaceholders(this.suffix)) / perRow
+	if(rows < 1) {
+		return 1
+	}
+	return rows
+}
+
+/*
+	countPlaceholders returns how many positional parameters the given [segments] are written with.
+*/
+func (this *BatchInsert) countPlaceholders(segments []segment) (int) {
+
+	if(this.template.options.Dialect.ReusesPlaceholders()) {
+		return len(segmentNames(segments))
+	}
+
+	count := 0
+	for _, segment := range segments {
+		if(segment.kind == parameterSegment) {
+			count++
+		}
+	}
+	return count
+}
+
+func (this *BatchInsert) fullTemplate() (*QueryTemplate) {
+
+	this.fullBatchOnce.Do(func() {
+		this.fullBatch = this.batchTemplate(this.rowsPerBatch)
+	})
+	return this.fullBatch
+}
+
+/*
+	batchTemplate builds a template which repeats the VALUES tuple for the given number of [rows].
+	The parameters of each row are given their own names, so that every row can be given its own values.
+*/
+func (this *BatchInsert) batchTemplate(rows int) (*QueryTemplate) {
+
+	var ret *QueryTemplate
+	var segments []segment
+
+	segments = make([]segment, 0, len(this.prefix) + (len(this.tuple) + 1) * rows + len(this.suffix))
+	segments = append(segments, this.prefix...)
+
+	for row := 0; row < rows; row++ {
+
+		if(row > 0) {
+			segments = append(segments, segment { kind: textSegment, text: ", " })
+		}
+
+		for _, tupleSegment := range this.tuple {
+
+			if(tupleSegment.kind == parameterSegment) {
+				tupleSegment.name = rowParameterName(tupleSegment.name, row)
+			}
+			segments = append(segments, tupleSegment)
+		}
+	}
+
+	segments = append(segments, this.suffix...)
+
+	ret = new(QueryTemplate)
+	ret.options = this.template.options
+	ret.segments = segments
+	ret.originalQuery = joinSegments(segments)
+	ret.build()
+	return ret
+}
+
+/*
+	rowParameterName returns the name given to the parameter of the given [name] in the given [row] of a batch.
+	It contains a character which can't be part of a parsed name, so it can't collide with any other parameter.
+*/
+func rowParameterName(name string, row int) (string) {
+	return name + "#" + strconv.Itoa(row)
+}
+
+/*
+	findValuesTuple returns the byte offsets of the start and end of the parenthesized tuple which follows
+	the first VALUES keyword in the given [template]'s query, or false if there isn't one.
+*/
+func findValuesTuple(template *QueryTemplate) (int, int, bool) {
+
+	var query string
+	var afterValues bool
+	var start int
+	var depth int
+	var offset int
+
+	query = template.originalQuery
+	tokens, _ := lexQuery(query, template.options)
+
+	// only plain SQL counts; a VALUES or parenthesis inside a string or comment is just text.
+	for _, token := range tokens {
+
+		if(token.kind != codeToken) {
+			continue
+		}
+
+		for i := 0; i < len(token.text); i++ {
+
+			offset = token.offset + i
+
+			switch {
+			case !afterValues:
+				afterValues = isKeywordAt(query, offset, "values")
+
+			case depth == 0 && token.text[i] == '(':
+				start = offset
+				depth = 1
+
+			case depth > 0 && token.text[i] == '(':
+				depth++
+
+			case depth > 0 && token.text[i] == ')':
+				depth--
+				if(depth == 0) {
+					return start, offset + 1, true
+				}
+			}
+		}
+	}
+	return 0, 0, false
+}
+
+/*
+	isKeywordAt returns true if the given [keyword] appears as a whole word at the given [offset] of [query],
+	regardless of case.
+*/
+func isKeywordAt(query string, offset int, keyword string) (bool) {
+
+	end := offset + len(keyword)
+
+	if(end > len(query) || !strings.EqualFold(query[offset:end], keyword)) {
+		return false
+	}
+	if(offset > 0 && isWordByte(query[offset - 1])) {
+		return false
+	}
+	return end == len(query) || !isWordByte(query[end])
+}
+
+/*
+	sliceSegments returns the parts of the given [segments] which cover the bytes from [start] to [end]
+	of the original query, splitting text segments where needed.
+*/
+func sliceSegments(segments []segment, start int, end int) ([]segment) {
+
+	var ret []segment
+	var from, to int
+
+	for _, piece := range segments {
+
+		if(piece.kind == parameterSegment) {
+
+			if(piece.offset >= start && piece.offset < end) {
+				ret = append(ret, piece)
+			}
+			continue
+		}
+
+		from = maximum(piece.offset, start)
+		to = minimum(piece.offset + len(piece.text), end)
+
+		if(from < to) {
+			piece.text = piece.text[from - piece.offset:to - piece.offset]
+			piece.offset = from
+			ret = append(ret, piece)
+		}
+	}
+	return ret
+}
+
+/*
+	segmentNames returns the set of parameter names in the given [segments].
+*/
+func segmentNames(segments []segment) (map[string]bool) {
+
+	ret := make(map[string]bool)
+
+	for _, segment := range segments {
+		if(segment.kind == parameterSegment) {
+			ret[segment.name] = true
+		}
+	}
+	return ret
+}
+
+/*
+	joinSegments returns the text of the given [segments], one after the other,
+	and updates each segment's offset to match.
+*/
+func joinSegments(segments []segment) (string) {
+
+	var builder strings.Builder
+
+	for index := range segments {
+		segments[index].offset = builder.Len()
+		builder.WriteString(segments[index].text)
+	}
+	return builder.String()
+}
+
+func maximum(left int, right int) (int) {
+
+	if(left > right) {
+		return left
+	}
+	return right
+}
@@ -0,0 +1,102 @@
+package namedParameterQuery
+
+import (
+	"testing"
+)
+
+type batchTestRow struct {
+	ID int `sqlParameterName:"id"`
+	Name string `sqlParameterName:"name"`
+}
+
+func TestBatchInsert(test *testing.T) {
+
+	batch, err := NewBatchInsert("INSERT INTO users (id, name) VALUES (:id, lower(:name)) ON CONFLICT (id) DO UPDATE SET source = :source",
+		QueryOptions { Dialect: PostgresDialect })
+
+	if(err != nil) {
+		test.Log("Unable to create batch insert: ", err)
+		test.FailNow()
+	}
+
+	rows := []map[string]interface{} {
+		map[string]interface{} { "id": 1, "name": "Alice", "source": "import" },
+		map[string]interface{} { "id": 2, "name": "Bob" },
+	}
+
+	bindings, err := batch.Bind(rows)
+	if(err != nil || len(bindings) != 1) {
+		test.Log("Expected one batch, got: ", len(bindings), err)
+		test.FailNow()
+	}
+
+	expected := "INSERT INTO users (id, name) VALUES ($1, lower($2)), ($3, lower($4)) ON CONFLICT (id) DO UPDATE SET source = $5"
+	if(bindings[0].GetParsedQuery() != expected) {
+		test.Log("Unexpected batch query: ", bindings[0].GetParsedQuery())
+		test.Fail()
+	}
+	verifyBindingParameters("BatchInsert", test, bindings[0], []interface{} { 1, "Alice", 2, "Bob", "import" })
+}
+
+func TestBatchInsertChunks(test *testing.T) {
+
+	var rows []batchTestRow
+
+	batch, err := NewBatchInsert("INSERT INTO users (id, name) values (:id, :name)", QueryOptions { Dialect: SQLServerDialect })
+	if(err != nil) {
+		test.Log("Unable to create batch insert: ", err)
+		test.FailNow()
+	}
+
+	if(batch.RowsPerBatch() != 1050) {
+		test.Log("Expected 1050 rows per batch, got: ", batch.RowsPerBatch())
+		test.Fail()
+	}
+
+	for i := 0; i < 2500; i++ {
+		rows = append(rows, batchTestRow { ID: i, Name: "user" })
+	}
+
+	bindings, err := batch.Bind(&rows)
+	if(err != nil || len(bindings) != 3) {
+		test.Log("Expected three batches, got: ", len(bindings), err)
+		test.FailNow()
+	}
+
+	for index, expected := range []int { 2100, 2100, 800 } {
+
+		parameters, err := bindings[index].Args()
+		if(err != nil || len(parameters) != expected) {
+			test.Log("Batch ", index, " expected ", expected, " parameters, got: ", len(parameters), err)
+			test.Fail()
+		}
+	}
+
+	if(bindings[2].GetParsedParameters()[0] != 2100 || bindings[2].GetParsedParameters()[799] != "user") {
+		test.Log("Expected the last batch to start from row 2100")
+		test.Fail()
+	}
+}
+
+func TestBatchInsertErrors(test *testing.T) {
+
+	_, err := NewBatchInsert("UPDATE users SET name = :name, note = 'VALUES (:x)'", QueryOptions{})
+	if(err == nil) {
+		test.Log("Expected a query without a VALUES tuple to fail")
+		test.Fail()
+	}
+
+	batch, _ := NewBatchInsert("INSERT INTO users (id) VALUES (:id)", QueryOptions{})
+
+	_, err = batch.Bind(batchTestRow{})
+	if(err == nil) {
+		test.Log("Expected rows which are not a slice to fail")
+		test.Fail()
+	}
+
+	bindings, err := batch.Bind([]batchTestRow{})
+	if(err != nil || len(bindings) != 0) {
+		test.Log("Expected no rows to give no batches")
+		test.Fail()
+	}
+}
@@ -15,6 +15,18 @@ func BenchmarkSimpleParsing(bench *testing.B) {
   }
 }
 
+/*
+  Benchmarks parsing a query without the help of DefaultTemplateCache
+*/
+func BenchmarkUncachedParsing(bench *testing.B) {
+
+  query := "SELECT [foo] FROM bar WHERE [baz] = :quux"
+  for i := 0; i < bench.N; i++ {
+
+    ParseQueryTemplate(query, QueryOptions{})
+  }
+}
+
 func BenchmarkMultiOccurrenceParsing(bench *testing.B) {
 
   query := "SELECT [foo] FROM bar WHERE [baz] = :quux " +
@@ -104,6 +116,24 @@ func BenchmarkMultiParameterReplacement(bench *testing.B) {
   }
 }
 
+/*
+  Benchmarks binding the same struct type over and over, which only needs its fields walked once
+*/
+func BenchmarkStructReplacement(bench *testing.B) {
+
+  query := "SELECT [foo] FROM bar WHERE [baz] = :Foo " +
+            "AND [something] = :Bar " +
+            "OR [otherStuff] NOT :Baz"
+  replacer := NewNamedParameterQuery(query)
+  parameters := SingleParameterTest { Foo: "foo", Bar: "bar", Baz: 15 }
+
+  for i := 0; i < bench.N; i++ {
+
+    replacer.SetValuesFromStruct(parameters)
+    replacer.GetParsedParameters()
+  }
+}
+
 func Benchmark16ParameterReplacement(bench *testing.B) {
     benchmarkMultiParameter(bench, 16)
 }
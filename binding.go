@@ -0,0 +1,446 @@
+package namedParameterQuery
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+/*
+	Binding holds the parameter values for a single execution of a QueryTemplate.
+	Bindings are cheap to create, and each one belongs to whoever created it;
+	a single Binding should not be used from more than one goroutine at a time.
+*/
+type Binding struct {
+
+	// The parsed query which this binding provides values for.
+	template *QueryTemplate
+
+	// Contains all positional parameters, in order, ready to be used in the positional query.
+	parameters []interface{}
+
+	// For each positional parameter, whether or not a value has been set for it.
+	bound []bool
+
+	// If set, names given to SetValue which do not appear in the query are recorded in [unknown].
+	strict bool
+	unknown []string
+
+	// The names of parameters whose values are slices, which need expanding into many placeholders.
+	expanded map[string]bool
+
+	// The query and parameters produced by render, which are only valid while [rendered] is set.
+	rendered bool
+	renderedQuery string
+	renderedParameters []interface{}
+
+	// The names of parameters which were given empty slices, as found by render.
+	empty []string
+
+	// Whether render left out any optional fragments.
+	dropped bool
+
+	// The quoted identifier set for each identifier parameter, by name.
+	identifiers map[string]string
+}
+
+/*
+	setTemplate makes this an empty binding of the given [template].
+*/
+func (this *Binding) setTemplate(template *QueryTemplate) {
+
+	this.template = template
+	this.parameters = make([]interface{}, template.parameterCount)
+	this.bound = make([]bool, template.parameterCount)
+	this.strict = false
+	this.unknown = nil
+	this.expanded = nil
+	this.identifiers = nil
+	this.rendered = false
+}
+
+/*
+	Template returns the parsed, immutable query which this binding provides values for.
+	It can be bound again any number of times.
+*/
+func (this *Binding) Template() (*QueryTemplate) {
+	return this.template
+}
+
+/*
+	GetParsedQuery returns a version of the original query text
+	whose named parameters have been replaced by positional parameters.
+
+	If any parameter has been given a slice as its value, it is written as one placeholder per element;
+	e.g. "WHERE id IN (:ids)" becomes "WHERE id IN (?, ?, ?)" for a slice of three ids.
+	An empty slice is written as "NULL".
+
+	If the query's options allow optional fragments, each fragment is only written if every parameter
+	inside it (but not inside a fragment nested within it) has been given a value, and its parent fragment is written.
+	e.g. an optional "AND status = :status" is left out of the query until "status" is set.
+*/
+func (this *Binding) GetParsedQuery() (string) {
+
+	this.render()
+	return this.renderedQuery
+}
+
+/*
+	GetParsedParameters returns an array of parameter objects that match the positional parameter list
+	from GetParsedQuery. Slice values are flattened, so that each element is its own parameter.
+*/
+func (this *Binding) GetParsedParameters() ([]interface{}) {
+
+	this.render()
+	return this.renderedParameters
+}
+
+/*
+	SetValue sets the value of the given [parameterName] to the given [parameterValue].
+	If the parsed query does not have a placeholder for the given [parameterName],
+	this method does nothing, unless the query is strict (see SetStrict).
+	Names are matched by the query's NameMapper; by default, they must match exactly.
+
+	If the [parameterValue] is a slice or array (other than a []byte, or a driver.Valuer),
+	each of its elements becomes a separate positional parameter. See GetParsedQuery.
+*/
+func (this *Binding) SetValue(parameterName string, parameterValue interface{}) {
+
+	var mappedName string
+
+	mappedName = this.template.options.Names(parameterName)
+
+	if(len(this.template.positions[mappedName]) == 0 && this.strict) {
+		this.unknown = appendUnique(this.unknown, parameterName)
+		return
+	}
+
+	this.setValue(mappedName, parameterValue)
+}
+
+/*
+	setValue sets the value of the parameter with the given (already mapped) [name].
+*/
+func (this *Binding) setValue(name string, value interface{}) {
+
+	for _, position := range this.template.positions[name] {
+		this.parameters[position] = value
+		this.bound[position] = true
+	}
+
+	if(isExpandable(value)) {
+
+		if(this.expanded == nil) {
+			this.expanded = make(map[string]bool, 4)
+		}
+		this.expanded[name] = true
+
+	} else {
+		delete(this.expanded, name)
+	}
+
+	// any rendered query and parameters are out of date, since they may be a copy made before this value was set.
+	this.rendered = false
+}
+
+/*
+	SetIdentifier sets the identifier parameter of the given [parameterName] (written in the query as ":!name")
+	to the given [identifier], which must be one of the [allowed] identifiers, or an error is returned.
+	The identifier is quoted by the query's dialect and written into the query itself, so it can name
+	a table or column (e.g. "ORDER BY :!column") where a positional parameter can't be used.
+	A dotted identifier, such as "users.name", has each of its parts quoted separately.
+
+	Since the allowed identifiers must be given here, identifier parameters are never set by SetValue,
+	SetValues, SetValuesFromMap, or SetValuesFromStruct.
+*/
+func (this *Binding) SetIdentifier(parameterName string, identifier string, allowed ...string) (error) {
+
+	var name string
+	var parts []string
+	var found bool
+
+	name = this.template.options.Names(parameterName)
+
+	if(!this.template.hasIdentifier(name)) {
+
+		if(this.strict) {
+			this.unknown = appendUnique(this.unknown, parameterName)
+		}
+		return nil
+	}
+
+	for _, candidate := range allowed {
+		if(candidate == identifier) {
+			found = true
+			break
+		}
+	}
+
+	if(!found) {
+		return fmt.Errorf("Unable to set identifier: '%s' is not an allowed value for %s", identifier, parameterName)
+	}
+
+	parts = strings.Split(identifier, ".")
+	for index, part := range parts {
+		parts[index] = this.template.options.Dialect.QuoteIdentifier(part)
+	}
+
+	if(this.identifiers == nil) {
+		this.identifiers = make(map[string]string, 2)
+	}
+	this.identifiers[name] = strings.Join(parts, ".")
+	this.rendered = false
+	return nil
+}
+
+/*
+	value returns the value of the parameter with the given (already mapped) [name], and whether it has been set.
+*/
+func (this *Binding) value(name string) (interface{}, bool) {
+
+	positions := this.template.positions[name]
+
+	if(len(positions) == 0 || !this.bound[positions[0]]) {
+		return nil, false
+	}
+	return this.parameters[positions[0]], true
+}
+
+/*
+	SetStrict determines whether or not this query complains about parameter names which it does not contain.
+	Once strict, any name given to SetValue, SetValues, SetValuesFromMap, or SetValuesFromStruct
+	which does not match a parameter in the query will be reported by Validate.
+
+	Whether strict or not, Validate always reports parameters which have no value set.
+*/
+func (this *Binding) SetStrict(strict bool) {
+	this.strict = strict
+}
+
+/*
+	appendUnique appends the given [name] to [names], unless it's already there.
+*/
+func appendUnique(names []string, name string) ([]string) {
+
+	for _, existing := range names {
+		if(existing == name) {
+			return names
+		}
+	}
+	return append(names, name)
+}
+
+/*
+	Validate returns a *BindingError if any parameter in this query has not had a value set,
+	if any parameter was given an empty slice (unless the query's options allow it, see EmptySlicePolicy),
+	or (if this query is strict) if any value was set for a name which does not appear in this query.
+	Every offending name is listed in the one error.
+	If all is well, nil is returned.
+*/
+func (this *Binding) Validate() (error) {
+
+	var missing []string
+
+	// parameters which only appear in optional fragments are left out along with their fragment.
+	for name, positions := range this.template.positions {
+		if(!this.bound[positions[0]] && !this.template.optionalNames[name]) {
+			missing = append(missing, name)
+		}
+	}
+
+	for _, name := range this.template.identifierNames {
+		if(len(this.identifiers[name]) == 0 && !this.template.optionalIdentifiers[name]) {
+			missing = append(missing, name)
+		}
+	}
+
+	this.render()
+
+	if(len(missing) == 0 && len(this.unknown) == 0 && len(this.empty) == 0 && len(this.template.slotNames) == 0) {
+		return nil
+	}
+	return newBindingError(missing, this.unknown, this.empty, this.template.slotNames)
+}
+
+/*
+	Args returns the same parameters as GetParsedParameters, but only if Validate finds no problems.
+	Otherwise, the error from Validate is returned.
+*/
+func (this *Binding) Args() ([]interface{}, error) {
+
+	err := this.Validate()
+	if(err != nil) {
+		return nil, err
+	}
+	return this.GetParsedParameters(), nil
+}
+
+/*
+	SetValuesFromMap uses every key/value pair in the given [parameters] as a parameter replacement
+	for this query. This is equivalent to calling SetValue for every key/value pair
+	in the given [parameters] map.
+	If there are any keys/values present in the map that aren't part of the query,
+	they are ignored, unless the query is strict (see SetStrict).
+*/
+func (this *Binding) SetValuesFromMap(parameters map[string]interface{}) {
+
+	for name, value := range parameters {
+		this.SetValue(name, value)
+	}
+}
+
+/*
+	SetValues sets values from the given [parameters], whatever kind of value that is.
+	A map whose keys are strings (or any string type) is used as SetValuesFromMap would, whatever its value type,
+	and a struct is used as SetValuesFromStruct would. Pointers and interfaces are followed to whatever they hold.
+	A nil [parameters] sets nothing. Anything else (including a nil pointer) returns an error.
+*/
+func (this *Binding) SetValues(parameters interface{}) (error) {
+
+	var value reflect.Value
+	var found bool
+
+	switch typed := parameters.(type) {
+	case nil:
+		return nil
+	case map[string]interface{}:
+		this.SetValuesFromMap(typed)
+		return nil
+	}
+
+	value, found = indirect(reflect.ValueOf(parameters))
+
+	if(!found) {
+		return errors.New("Unable to add query values from parameter: parameter is nil")
+	}
+
+	switch value.Kind() {
+	case reflect.Struct:
+		this.setStructValues(value, "")
+		return nil
+
+	case reflect.Map:
+		if(value.Type().Key().Kind() == reflect.String) {
+			this.setMapValues(value)
+			return nil
+		}
+	}
+	return errors.New("Unable to add query values from parameter: parameter is not a struct, or a map with string keys")
+}
+
+/*
+	setMapValues sets every entry of the given [mapValue], whose keys must be of a string kind.
+*/
+func (this *Binding) setMapValues(mapValue reflect.Value) {
+
+	iterator := mapValue.MapRange()
+
+	for iterator.Next() {
+		this.SetValue(iterator.Key().String(), iterator.Value().Interface())
+	}
+}
+
+/*
+	SetValuesFromStruct uses reflection to find every public field of the given struct [parameters]
+	and set their key/value as named parameters in this query.
+	If the given [parameters] is not a struct, or a pointer to one, this will return an error.
+
+	If you do not wish for a field in the struct to be added by its literal name,
+	The struct may optionally specify the sqlParameterName as a tag on the field.
+	e.g., a struct field may say something like:
+
+		type Test struct {
+			Foo string `sqlParameterName:"foobar"`
+		}
+
+	If a field has no sqlParameterName tag, its "db" tag is used instead.
+	The name in a tag may be followed by options: "omitempty" leaves the parameter unset when the field is a zero value,
+	and "default=value" sets the given value instead. A tag of "-" leaves the field out altogether. e.g.:
+
+		type Search struct {
+			Status string `sqlParameterName:"status,omitempty"`
+			Limit int `db:"limit,default=50"`
+			Cache *Cache `sqlParameterName:"-"`
+		}
+
+	The fields of embedded structs are set as though they belonged to the outer struct.
+	The fields of other nested structs are named after the field which holds them, joined by the query's FieldSeparator;
+	e.g. field City of field Address is set as "Address.City". A nil pointer to a nested struct sets nothing.
+	Structs which implement driver.Valuer, and time.Time, are set as single values.
+*/
+func (this *Binding) SetValuesFromStruct(parameters interface{}) (error) {
+
+	var fieldValues reflect.Value
+	var found bool
+
+	fieldValues, found = indirect(reflect.ValueOf(parameters))
+
+	if(!found) {
+		return errors.New("Unable to add query values from parameter: parameter is nil")
+	}
+
+	if(fieldValues.Kind() != reflect.Struct) {
+		return errors.New("Unable to add query values from parameter: parameter is not a struct")
+	}
+
+	this.setStructValues(fieldValues, "")
+	return nil
+}
+
+/*
+	setStructValues sets every field of the given [structValue], each named with the given [prefix].
+*/
+func (this *Binding) setStructValues(structValue reflect.Value, prefix string) {
+
+	var fieldValue reflect.Value
+	var found bool
+	var fields []structField
+	var names NameMapper
+
+	fields = structFields(structValue.Type(), parameterTags)
+	names = this.template.options.Names
+
+	// fields whose names differ may still map to the same parameter, and shadow each other by their mapped names.
+	if(!isExactNames(names)) {
+		fields = resolveShadowing(fields, func(name string) string {
+			return names(prefix + name)
+		})
+	}
+
+	for _, field := range fields {
+
+		fieldValue, found = readFieldByIndex(structValue, field.index)
+		if(!found) {
+			continue
+		}
+
+		if(fieldValue.IsZero()) {
+
+			if(field.defaultValue != nil) {
+				this.SetValue(prefix + field.name, field.defaultValue)
+				continue
+			}
+
+			if(field.omitEmpty) {
+				continue
+			}
+		}
+
+		if(field.nested) {
+
+			if(fieldValue.Kind() == reflect.Ptr) {
+
+				if(fieldValue.IsNil()) {
+					continue
+				}
+				fieldValue = fieldValue.Elem()
+			}
+
+			this.setStructValues(fieldValue, prefix + field.name + this.template.options.FieldSeparator)
+			continue
+		}
+
+		this.SetValue(prefix + field.name, fieldValue.Interface())
+	}
+}
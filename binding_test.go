@@ -0,0 +1,212 @@
+package namedParameterQuery
+
+import (
+	"sync"
+	"testing"
+)
+
+/*
+	Ensures that bindings of one template are independent of each other.
+*/
+func TestIndependentBindings(test *testing.T) {
+
+	template := MustParseQueryTemplate("SELECT * FROM table WHERE col1 = :foo AND col2 = :bar", QueryOptions{})
+
+	first := template.Bind()
+	second := template.Bind()
+
+	first.SetValue("foo", 1)
+	second.SetValue("foo", 2)
+	second.SetValue("bar", 3)
+
+	verifyBindingParameters("FirstBinding", test, first, []interface{} { 1, nil })
+	verifyBindingParameters("SecondBinding", test, second, []interface{} { 2, 3 })
+
+	if(first.Template() != template || second.GetParsedQuery() != template.GetParsedQuery()) {
+		test.Log("Expected bindings to share their template")
+		test.Fail()
+	}
+
+	if(first.Validate() == nil || second.Validate() != nil) {
+		test.Log("Expected only the incomplete binding to fail validation")
+		test.Fail()
+	}
+}
+
+/*
+	Ensures that one template can be bound from many goroutines at once.
+	Run with -race to be sure.
+*/
+func TestConcurrentBindings(test *testing.T) {
+
+	var waitGroup sync.WaitGroup
+
+	template := MustParseQueryTemplate("SELECT * FROM table WHERE col1 = :foo AND col2 = :foo", QueryOptions { Dialect: PostgresDialect })
+
+	for i := 0; i < 16; i++ {
+
+		waitGroup.Add(1)
+		go func(value int) {
+
+			defer waitGroup.Done()
+
+			binding := template.Bind()
+			binding.SetValue("foo", value)
+			verifyBindingParameters("ConcurrentBinding", test, binding, []interface{} { value })
+		}(i)
+	}
+
+	waitGroup.Wait()
+}
+
+func TestMustParseQueryTemplatePanics(test *testing.T) {
+
+	defer func() {
+		if(recover() == nil) {
+			test.Log("Expected a malformed query to panic")
+			test.Fail()
+		}
+	}()
+
+	MustParseQueryTemplate("SELECT 'oops", QueryOptions{})
+}
+
+func verifyBindingParameters(testName string, test *testing.T, binding *Binding, expectedParameters []interface{}) {
+
+	actualParameters := binding.GetParsedParameters()
+
+	if(len(actualParameters) != len(expectedParameters)) {
+		test.Log("Test ", testName, ": Actual parameters (", len(actualParameters), ") did not match expected parameters (", len(expectedParameters), ")")
+		test.Fail()
+		return
+	}
+
+	for index, parameter := range actualParameters {
+		if(parameter != expectedParameters[index]) {
+			test.Log("Test ", testName, ": Actual parameter at position ", index, " (", parameter, ") did not match expected parameter (", expectedParameters[index], ")")
+			test.Fail()
+		}
+	}
+}
+
+type bindingTestKey string
+
+func TestSetValues(test *testing.T) {
+
+	var binding *Binding
+	var pointer *SingleParameterTest
+	var boxed interface{}
+
+	template := MustParseQueryTemplate("SELECT * FROM table WHERE col1 = :Foo AND col2 = :Baz", QueryOptions{})
+
+	pointer = &SingleParameterTest { Foo: "foo", Baz: 15 }
+	boxed = pointer
+
+	parameterSets := map[string]interface{} {
+		"StructPointer": pointer,
+		"PointerToInterface": &boxed,
+		"StringMap": map[string]string { "Foo": "foo", "Baz": "15" },
+		"StringKindKeys": map[bindingTestKey]interface{} { "Foo": "foo", "Baz": 15 },
+	}
+
+	for name, parameters := range parameterSets {
+
+		binding = template.Bind()
+
+		err := binding.SetValues(parameters)
+		if(err == nil) {
+			err = binding.Validate()
+		}
+
+		if(err != nil) {
+			test.Log("Test '", name, "': Unexpected error: ", err)
+			test.Fail()
+		}
+	}
+
+	binding = template.Bind()
+	verifyBindingParameters("StructPointerValues", test, binding, []interface{} { nil, nil })
+
+	binding.SetValuesFromStruct(pointer)
+	verifyBindingParameters("StructPointerValues", test, binding, []interface{} { "foo", 15 })
+
+	invalidSets := map[string]interface{} {
+		"NilPointer": (*SingleParameterTest)(nil),
+		"IntegerKeys": map[int]interface{} { 1: "foo" },
+		"Scalar": 5,
+	}
+
+	for name, parameters := range invalidSets {
+
+		if(template.Bind().SetValues(parameters) == nil) {
+			test.Log("Test '", name, "': Expected unusable parameters to fail")
+			test.Fail()
+		}
+	}
+}
+
+func TestSetIdentifier(test *testing.T) {
+
+	var binding *Binding
+
+	columns := []string { "name", "users.created_at" }
+	template := MustParseQueryTemplate("SELECT * FROM users WHERE team = :team ORDER BY :!sort, :!sort DESC",
+		QueryOptions { Dialect: PostgresDialect })
+
+	if(!equalNames(template.IdentifierNames(), []string { "sort" }) || !equalNames(template.ParameterNames(), []string { "team" })) {
+		test.Log("Expected identifier parameters to be kept apart from value parameters")
+		test.Fail()
+	}
+
+	binding = template.Bind()
+	binding.SetValue("team", 1)
+	binding.SetValue("sort", "name")
+
+	if(binding.Validate() == nil) {
+		test.Log("Expected an identifier parameter to be left unset by SetValue")
+		test.Fail()
+	}
+
+	err := binding.SetIdentifier("sort", "password", columns...)
+	if(err == nil) {
+		test.Log("Expected an identifier outside the allowed list to fail")
+		test.Fail()
+	}
+
+	err = binding.SetIdentifier("sort", "users.created_at", columns...)
+	if(err == nil) {
+		err = binding.Validate()
+	}
+
+	if(err != nil) {
+		test.Log("Unexpected error setting an allowed identifier: ", err)
+		test.Fail()
+	}
+
+	expected := "SELECT * FROM users WHERE team = $1 ORDER BY \"users\".\"created_at\", \"users\".\"created_at\" DESC"
+	if(binding.GetParsedQuery() != expected) {
+		test.Log("Unexpected query with identifier: ", binding.GetParsedQuery())
+		test.Fail()
+	}
+	verifyBindingParameters("Identifier", test, binding, []interface{} { 1 })
+}
+
+/*
+	Ensures that setting a value after the parameters were read is seen, even though writing an identifier
+	means the parameters were copied.
+*/
+func TestSetIdentifierRebinding(test *testing.T) {
+
+	binding := MustParseQueryTemplate("SELECT * FROM users WHERE a = :a ORDER BY :!sort", QueryOptions{}).Bind()
+	binding.SetIdentifier("sort", "name", "name")
+	binding.SetValue("a", 1)
+
+	_, err := binding.Args()
+	if(err != nil) {
+		test.Log("Unable to bind query: ", err)
+		test.FailNow()
+	}
+
+	binding.SetValue("a", 2)
+	verifyBindingParameters("IdentifierRebinding", test, binding, []interface{} { 2 })
+}
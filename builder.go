@@ -0,0 +1,546 @@
+package namedParameterQuery
+
+import (
+	"bytes"
+	"errors"
+	"strconv"
+	"strings"
+)
+
+/*
+	Represents which kind of statement a QueryBuilder builds.
+*/
+type statementKind int
+
+const (
+	selectStatement statementKind = iota
+	insertStatement
+	updateStatement
+	deleteStatement
+)
+
+/*
+	QueryBuilder builds a named parameter query one clause at a time, e.g.:
+
+		query, err := Select("id", "name").
+			From("users").
+			Where(Eq("team", team)).
+			And(In("status", []string { "active", "invited" })).
+			OrderBy("name").
+			Limit(10).
+			Build()
+
+	Every value is given its own named parameter (named after its column), and the query is parsed
+	in the same way as any other, so slices are expanded, and dialects respected, as usual.
+
+	Table and column names are written into the query as they are given, so they should never come from user input;
+	see Binding.SetIdentifier for that.
+*/
+type QueryBuilder struct {
+
+	kind statementKind
+	options QueryOptions
+	table string
+
+	// For selects, the columns to select.
+	columns []string
+
+	// For inserts and updates, the columns to set, and their values.
+	assignments []assignment
+
+	// Every condition of the WHERE clause, each with the conjunction which joins it to the one before.
+	conditions []condition
+
+	orderBy []string
+	limit int
+	hasLimit bool
+
+	// The parameters named while rendering, and their values.
+	parameters map[string]interface{}
+	parameterNames []string
+
+	// The first problem found while rendering, if any.
+	err error
+}
+
+type assignment struct {
+	column string
+	value interface{}
+}
+
+type condition struct {
+	conjunction string
+	predicate Predicate
+}
+
+var statementNames = map[statementKind]string {
+	selectStatement: "SELECT",
+	insertStatement: "INSERT",
+	updateStatement: "UPDATE",
+	deleteStatement: "DELETE",
+}
+
+/*
+	Predicate is a single condition of a WHERE clause, such as Eq("id", 5).
+	Predicates can be grouped with All and Any.
+*/
+type Predicate struct {
+
+	// Writes this predicate for the given builder, naming a parameter for each of its values.
+	render func(builder *QueryBuilder) string
+}
+
+/*
+	Select starts building a SELECT of the given [columns], or of "*" if none are given.
+*/
+func Select(columns ...string) (*QueryBuilder) {
+
+	if(len(columns) == 0) {
+		columns = []string { "*" }
+	}
+	return &QueryBuilder { kind: selectStatement, columns: columns }
+}
+
+/*
+	Insert starts building an INSERT into the given [table]. Use Value to give each column's value.
+*/
+func Insert(table string) (*QueryBuilder) {
+	return &QueryBuilder { kind: insertStatement, table: table }
+}
+
+/*
+	Update starts building an UPDATE of the given [table]. Use Set to give each column's new value.
+*/
+func Update(table string) (*QueryBuilder) {
+	return &QueryBuilder { kind: updateStatement, table: table }
+}
+
+/*
+	Delete starts building a DELETE from the given [table].
+*/
+func Delete(table string) (*QueryBuilder) {
+	return &QueryBuilder { kind: deleteStatement, table: table }
+}
+
+/*
+	WithOptions sets the options with which the built query is parsed, e.g. its dialect.
+*/
+func (this *QueryBuilder) WithOptions(options QueryOptions) (*QueryBuilder) {
+	this.options = options
+	return this
+}
+
+/*
+	From sets the table which a SELECT reads from.
+*/
+func (this *QueryBuilder) From(table string) (*QueryBuilder) {
+	this.table = table
+	return this
+}
+
+/*
+	Value sets the value of the given [column] for an INSERT.
+*/
+func (this *QueryBuilder) Value(column string, value interface{}) (*QueryBuilder) {
+	this.assignments = append(this.assignments, assignment { column: column, value: value })
+	return this
+}
+
+/*
+	Set sets the new value of the given [column] for an UPDATE.
+*/
+func (this *QueryBuilder) Set(column string, value interface{}) (*QueryBuilder) {
+	this.assignments = append(this.assignments, assignment { column: column, value: value })
+	return this
+}
+
+/*
+	Where adds the given [predicate] to the WHERE clause. If there's already a condition, this is the same as And.
+*/
+func (this *QueryBuilder) Where(predicate Predicate) (*QueryBuilder) {
+	return this.And(predicate)
+}
+
+/*
+	And adds the given [predicate] to the WHERE clause, joined to the condition before it by AND.
+*/
+func (this *QueryBuilder) And(predicate Predicate) (*QueryBuilder) {
+	this.conditions = append(this.conditions, condition { conjunction: " AND ", predicate: predicate })
+	return this
+}
+
+/*
+	Or adds the given [predicate] to the WHERE clause, joined to the condition before it by OR.
+	Since SQL binds AND more tightly than OR, use All and Any to group conditions where it matters.
+*/
+func (this *QueryBuilder) Or(predicate Predicate) (*QueryBuilder) {
+	this.conditions = append(this.conditions, condition { conjunction: " OR ", predicate: predicate })
+	return this
+}
+
+/*
+	OrderBy adds the given [columns] to the ORDER BY clause. Each may include a direction, e.g. "name DESC".
+*/
+func (this *QueryBuilder) OrderBy(columns ...string) (*QueryBuilder) {
+	this.orderBy = append(this.orderBy, columns...)
+	return this
+}
+
+/*
+	Limit sets the most rows a SELECT returns.
+*/
+func (this *QueryBuilder) Limit(count int) (*QueryBuilder) {
+	this.limit = count
+	this.hasLimit = true
+	return this
+}
+
+/*
+	Text returns the query built so far, with named parameters, as it would be parsed by Build.
+*/
+func (this *QueryBuilder) Text() (string, error) {
+	return this.render()
+}
+
+/*
+	Build parses the query built so far, and sets the value of each of its parameters.
+*/
+func (this *QueryBuilder) Build() (*NamedParameterQuery, error) {
+
+	queryText, err := this.render()
+	if(err != nil) {
+		return nil, err
+	}
+
+	query, err := ParseNamedParameterQueryWithOptions(queryText, this.options)
+	if(err != nil) {
+		return nil, err
+	}
+
+	for _, name := range this.parameterNames {
+		query.SetValue(name, this.parameters[name])
+	}
+	return query, nil
+}
+
+func (this *QueryBuilder) render() (string, error) {
+
+	var builder bytes.Buffer
+
+	err := this.check()
+	if(err != nil) {
+		return "", err
+	}
+
+	this.parameters = make(map[string]interface{})
+	this.parameterNames = nil
+	this.err = nil
+
+	switch this.kind {
+	case selectStatement:
+		builder.WriteString("SELECT " + strings.Join(this.columns, ", ") + " FROM " + this.table)
+
+	case insertStatement:
+		columns := make([]string, len(this.assignments))
+		values := make([]string, len(this.assignments))
+
+		for index, assignment := range this.assignments {
+			columns[index] = assignment.column
+			values[index] = this.parameter(assignment.column, assignment.value)
+		}
+		builder.WriteString("INSERT INTO " + this.table + " (" + strings.Join(columns, ", ") + ") VALUES (" + strings.Join(values, ", ") + ")")
+		return builder.String(), nil
+
+	case updateStatement:
+		builder.WriteString("UPDATE " + this.table + " SET ")
+
+		for index, assignment := range this.assignments {
+			if(index > 0) {
+				builder.WriteString(", ")
+			}
+			builder.WriteString(assignment.column + " = " + this.parameter(assignment.column, assignment.value))
+		}
+
+	case deleteStatement:
+		builder.WriteString("DELETE FROM " + this.table)
+	}
+
+	for index, condition := range this.conditions {
+
+		if(index == 0) {
+			builder.WriteString(" WHERE ")
+		} else {
+			builder.WriteString(condition.conjunction)
+		}
+		builder.WriteString(condition.predicate.write(this))
+	}
+
+	if(this.err != nil) {
+		return "", this.err
+	}
+
+	if(this.kind != selectStatement) {
+		return builder.String(), nil
+	}
+
+	if(len(this.orderBy) > 0) {
+		builder.WriteString(" ORDER BY " + strings.Join(this.orderBy, ", "))
+	}
+
+	if(this.hasLimit) {
+		builder.WriteString(this.limitClause())
+	}
+	return builder.String(), nil
+}
+
+/*
+	check returns an error if anything is missing from this builder's statement,
+	or if it has a clause which doesn't belong in that kind of statement.
+*/
+func (this *QueryBuilder) check() (error) {
+
+	statement := statementNames[this.kind]
+
+	switch {
+	case len(this.table) == 0:
+		return errors.New("Unable to build query: no table given")
+
+	case len(this.assignments) == 0 && (this.kind == insertStatement || this.kind == updateStatement):
+		return errors.New("Unable to build " + statement + " query: no values given")
+
+	case len(this.assignments) > 0 && (this.kind == selectStatement || this.kind == deleteStatement):
+		return errors.New("Unable to build " + statement + " query: values can only be given to an INSERT or UPDATE")
+
+	case len(this.conditions) > 0 && this.kind == insertStatement:
+		return errors.New("Unable to build INSERT query: WHERE can't be used in an INSERT")
+
+	case len(this.orderBy) > 0 && this.kind != selectStatement:
+		return errors.New("Unable to build " + statement + " query: ORDER BY can only be used in a SELECT")
+
+	case this.hasLimit && this.kind != selectStatement:
+		return errors.New("Unable to build " + statement + " query: LIMIT can only be used in a SELECT")
+	}
+	return nil
+}
+
+/*
+	limitClause returns the clause which limits the rows of a SELECT, which depends on the dialect.
+*/
+func (this *QueryBuilder) limitClause() (string) {
+
+	count := strconv.Itoa(this.limit)
+
+	switch this.options.withDefaults().Dialect {
+	case SQLServerDialect:
+		// SQL Server can only fetch a number of rows from an ordered query.
+		if(len(this.orderBy) == 0) {
+			return " ORDER BY (SELECT NULL) OFFSET 0 ROWS FETCH NEXT " + count + " ROWS ONLY"
+		}
+		return " OFFSET 0 ROWS FETCH NEXT " + count + " ROWS ONLY"
+
+	case OracleDialect:
+		return " FETCH FIRST " + count + " ROWS ONLY"
+	}
+	return " LIMIT " + count
+}
+
+/*
+	parameter names a new parameter after the given [column], sets its [value], and returns the parameter
+	as it should be written into the query. A column used more than once gets a numbered name each time after the first.
+*/
+func (this *QueryBuilder) parameter(column string, value interface{}) (string) {
+
+	var base string
+	var name string
+	var options QueryOptions
+
+	options = this.options.withDefaults()
+	base = parameterBaseName(column)
+	name = base
+
+	for suffix := 2; this.isNamed(options.Names(name)); suffix++ {
+		name = base + "_" + strconv.Itoa(suffix)
+	}
+
+	this.parameters[name] = value
+	this.parameterNames = append(this.parameterNames, name)
+
+	switch {
+	case options.Prefixes & ColonPrefix != 0:
+		return ":" + name
+	case options.Prefixes & AtPrefix != 0:
+		return "@" + name
+	case options.Prefixes & DollarPrefix != 0:
+		return "$" + name
+	case options.Prefixes & DollarBracePrefix != 0:
+		return "${" + name + "}"
+	}
+	return "#{" + name + "}"
+}
+
+/*
+	isNamed returns true if a parameter whose name maps to the given [mapped] name has already been named.
+*/
+func (this *QueryBuilder) isNamed(mapped string) (bool) {
+
+	names := this.options.withDefaults().Names
+
+	for _, existing := range this.parameterNames {
+		if(names(existing) == mapped) {
+			return true
+		}
+	}
+	return false
+}
+
+/*
+	parameterBaseName turns the given [column] into a parameter name which every built-in identifier policy allows,
+	e.g. "users.created_at" becomes "users_created_at".
+*/
+func parameterBaseName(column string) (string) {
+
+	var builder strings.Builder
+
+	for _, character := range strings.ToLower(column) {
+
+		if(ASCIIIdentifiers(character)) {
+			builder.WriteRune(character)
+		} else {
+			builder.WriteByte('_')
+		}
+	}
+
+	name := strings.Trim(builder.String(), "_")
+	if(len(name) == 0) {
+		return "p"
+	}
+	return name
+}
+
+/*
+	write writes this predicate for the given [builder]. The zero Predicate can't be written,
+	so building a query with one fails, rather than panicking.
+*/
+func (this Predicate) write(builder *QueryBuilder) (string) {
+
+	if(this.render == nil) {
+		builder.fail(errors.New("Unable to build query: empty predicate; use Eq, In, etc. to make one"))
+		return ""
+	}
+	return this.render(builder)
+}
+
+/*
+	comparison returns a predicate which compares the given [column] to a parameter holding the given [value].
+*/
+func comparison(column string, operator string, value interface{}) (Predicate) {
+
+	return Predicate { render: func(builder *QueryBuilder) string {
+		return column + " " + operator + " " + builder.parameter(column, value)
+	}}
+}
+
+// Eq matches rows whose [column] equals the given [value]. A nil value matches rows where the column IS NULL.
+func Eq(column string, value interface{}) (Predicate) {
+
+	if(value == nil) {
+		return Predicate { render: func(builder *QueryBuilder) string { return column + " IS NULL" } }
+	}
+	return comparison(column, "=", value)
+}
+
+// Ne matches rows whose [column] does not equal the given [value]. A nil value matches rows where the column IS NOT NULL.
+func Ne(column string, value interface{}) (Predicate) {
+
+	if(value == nil) {
+		return Predicate { render: func(builder *QueryBuilder) string { return column + " IS NOT NULL" } }
+	}
+	return comparison(column, "<>", value)
+}
+
+// Lt matches rows whose [column] is less than the given [value].
+func Lt(column string, value interface{}) (Predicate) {
+	return comparison(column, "<", value)
+}
+
+// Le matches rows whose [column] is less than or equal to the given [value].
+func Le(column string, value interface{}) (Predicate) {
+	return comparison(column, "<=", value)
+}
+
+// Gt matches rows whose [column] is greater than the given [value].
+func Gt(column string, value interface{}) (Predicate) {
+	return comparison(column, ">", value)
+}
+
+// Ge matches rows whose [column] is greater than or equal to the given [value].
+func Ge(column string, value interface{}) (Predicate) {
+	return comparison(column, ">=", value)
+}
+
+// Like matches rows whose [column] matches the given LIKE [pattern].
+func Like(column string, pattern interface{}) (Predicate) {
+	return comparison(column, "LIKE", pattern)
+}
+
+/*
+	In matches rows whose [column] equals any of the given [values], which should be a slice.
+	The slice is expanded into one positional parameter per element; see GetParsedQuery.
+*/
+func In(column string, values interface{}) (Predicate) {
+
+	return Predicate { render: func(builder *QueryBuilder) string {
+		return column + " IN (" + builder.parameter(column, values) + ")"
+	}}
+}
+
+// Between matches rows whose [column] is between [low] and [high], inclusive.
+func Between(column string, low interface{}, high interface{}) (Predicate) {
+
+	return Predicate { render: func(builder *QueryBuilder) string {
+		return column + " BETWEEN " + builder.parameter(column, low) + " AND " + builder.parameter(column, high)
+	}}
+}
+
+/*
+	All matches rows which match every one of the given [predicates], grouped in parentheses.
+	Building a query with no predicates given to All fails, rather than matching (or deleting) every row.
+*/
+func All(predicates ...Predicate) (Predicate) {
+	return group("All", " AND ", predicates)
+}
+
+/*
+	Any matches rows which match at least one of the given [predicates], grouped in parentheses.
+	Building a query with no predicates given to Any fails, rather than matching no rows.
+*/
+func Any(predicates ...Predicate) (Predicate) {
+	return group("Any", " OR ", predicates)
+}
+
+func group(function string, conjunction string, predicates []Predicate) (Predicate) {
+
+	return Predicate { render: func(builder *QueryBuilder) string {
+
+		if(len(predicates) == 0) {
+			builder.fail(errors.New("Unable to build query: no predicates given to " + function))
+			return "()"
+		}
+
+		rendered := make([]string, len(predicates))
+		for index, predicate := range predicates {
+			rendered[index] = predicate.write(builder)
+		}
+		return "(" + strings.Join(rendered, conjunction) + ")"
+	}}
+}
+
+/*
+	fail records the given [err], unless an earlier problem was already found.
+*/
+func (this *QueryBuilder) fail(err error) {
+
+	if(this.err == nil) {
+		this.err = err
+	}
+}
@@ -0,0 +1,188 @@
+package namedParameterQuery
+
+import (
+	"testing"
+)
+
+/*
+	Represents a single test of the query builder.
+	The [Builder] must build a query whose original text matches [ExpectedText],
+	whose parsed text matches [ExpectedQuery], and whose parameters match [ExpectedParameters].
+*/
+type BuilderTest struct {
+	Name string
+	Builder *QueryBuilder
+	ExpectedText string
+	ExpectedQuery string
+	ExpectedParameters []interface{}
+}
+
+func TestQueryBuilder(test *testing.T) {
+
+	builderTests := []BuilderTest {
+		BuilderTest {
+			Name: "Select",
+			Builder: Select("id", "name").From("users").Where(Eq("team", 3)).And(Like("name", "a%")).OrderBy("name DESC").Limit(10),
+			ExpectedText: "SELECT id, name FROM users WHERE team = :team AND name LIKE :name ORDER BY name DESC LIMIT 10",
+			ExpectedQuery: "SELECT id, name FROM users WHERE team = ? AND name LIKE ? ORDER BY name DESC LIMIT 10",
+			ExpectedParameters: []interface{} { 3, "a%" },
+		},
+		BuilderTest {
+			Name: "SelectAll",
+			Builder: Select().From("users"),
+			ExpectedText: "SELECT * FROM users",
+			ExpectedQuery: "SELECT * FROM users",
+			ExpectedParameters: []interface{} {},
+		},
+		BuilderTest {
+			Name: "In",
+			Builder: Select("id").From("users").Where(In("status", []string { "active", "invited" })),
+			ExpectedText: "SELECT id FROM users WHERE status IN (:status)",
+			ExpectedQuery: "SELECT id FROM users WHERE status IN (?, ?)",
+			ExpectedParameters: []interface{} { "active", "invited" },
+		},
+		BuilderTest {
+			Name: "Between",
+			Builder: Select("id").From("orders").Where(Between("orders.total", 10, 20)).And(Ge("orders.total", 5)),
+			ExpectedText: "SELECT id FROM orders WHERE orders.total BETWEEN :orders_total AND :orders_total_2 AND orders.total >= :orders_total_3",
+			ExpectedQuery: "SELECT id FROM orders WHERE orders.total BETWEEN ? AND ? AND orders.total >= ?",
+			ExpectedParameters: []interface{} { 10, 20, 5 },
+		},
+		BuilderTest {
+			Name: "Grouped",
+			Builder: Select("id").From("users").Where(Eq("team", 1)).And(Any(Eq("role", "admin"), Ne("deleted_at", nil))),
+			ExpectedText: "SELECT id FROM users WHERE team = :team AND (role = :role OR deleted_at IS NOT NULL)",
+			ExpectedQuery: "SELECT id FROM users WHERE team = ? AND (role = ? OR deleted_at IS NOT NULL)",
+			ExpectedParameters: []interface{} { 1, "admin" },
+		},
+		BuilderTest {
+			Name: "Or",
+			Builder: Select("id").From("users").Where(Eq("role", "admin")).Or(Eq("role", "owner")).WithOptions(QueryOptions { Dialect: PostgresDialect }),
+			ExpectedText: "SELECT id FROM users WHERE role = :role OR role = :role_2",
+			ExpectedQuery: "SELECT id FROM users WHERE role = $1 OR role = $2",
+			ExpectedParameters: []interface{} { "admin", "owner" },
+		},
+		BuilderTest {
+			Name: "Insert",
+			Builder: Insert("users").Value("name", "alice").Value("team", 2),
+			ExpectedText: "INSERT INTO users (name, team) VALUES (:name, :team)",
+			ExpectedQuery: "INSERT INTO users (name, team) VALUES (?, ?)",
+			ExpectedParameters: []interface{} { "alice", 2 },
+		},
+		BuilderTest {
+			Name: "Update",
+			Builder: Update("users").Set("team", 4).Where(Eq("team", 2)),
+			ExpectedText: "UPDATE users SET team = :team WHERE team = :team_2",
+			ExpectedQuery: "UPDATE users SET team = ? WHERE team = ?",
+			ExpectedParameters: []interface{} { 4, 2 },
+		},
+		BuilderTest {
+			Name: "Delete",
+			Builder: Delete("users").Where(Lt("created_at", "2020-01-01")).And(Eq("deleted_at", nil)),
+			ExpectedText: "DELETE FROM users WHERE created_at < :created_at AND deleted_at IS NULL",
+			ExpectedQuery: "DELETE FROM users WHERE created_at < ? AND deleted_at IS NULL",
+			ExpectedParameters: []interface{} { "2020-01-01" },
+		},
+		BuilderTest {
+			Name: "SQLServerLimit",
+			Builder: Select("id").From("users").Where(Gt("age", 18)).Limit(5).WithOptions(QueryOptions { Dialect: SQLServerDialect }),
+			ExpectedText: "SELECT id FROM users WHERE age > :age ORDER BY (SELECT NULL) OFFSET 0 ROWS FETCH NEXT 5 ROWS ONLY",
+			ExpectedQuery: "SELECT id FROM users WHERE age > @p1 ORDER BY (SELECT NULL) OFFSET 0 ROWS FETCH NEXT 5 ROWS ONLY",
+			ExpectedParameters: []interface{} { 18 },
+		},
+		BuilderTest {
+			Name: "AtPrefix",
+			Builder: Select("id").From("users").Where(Eq("name", "bob")).WithOptions(QueryOptions { Prefixes: AtPrefix }),
+			ExpectedText: "SELECT id FROM users WHERE name = @name",
+			ExpectedQuery: "SELECT id FROM users WHERE name = ?",
+			ExpectedParameters: []interface{} { "bob" },
+		},
+		BuilderTest {
+			Name: "CaseInsensitiveNames",
+			Builder: Select("id").From("users").Where(Eq("Name", "bob")).Or(Eq("name", "carol")).WithOptions(QueryOptions { Names: CaseInsensitiveNames }),
+			ExpectedText: "SELECT id FROM users WHERE Name = :name OR name = :name_2",
+			ExpectedQuery: "SELECT id FROM users WHERE Name = ? OR name = ?",
+			ExpectedParameters: []interface{} { "bob", "carol" },
+		},
+	}
+
+	for _, builderTest := range builderTests {
+
+		text, err := builderTest.Builder.Text()
+		if(err != nil) {
+			test.Log("Test '", builderTest.Name, "': Unable to build query: ", err)
+			test.Fail()
+			continue
+		}
+
+		if(text != builderTest.ExpectedText) {
+			test.Log("Test '", builderTest.Name, "': Expected built text did not match actual output")
+			test.Log("Actual: ", text)
+			test.Fail()
+		}
+
+		query, err := builderTest.Builder.Build()
+		if(err != nil) {
+			test.Log("Test '", builderTest.Name, "': Unable to build query: ", err)
+			test.Fail()
+			continue
+		}
+
+		if(query.GetParsedQuery() != builderTest.ExpectedQuery) {
+			test.Log("Test '", builderTest.Name, "': Expected parsed query did not match actual output")
+			test.Log("Actual: ", query.GetParsedQuery())
+			test.Fail()
+		}
+
+		verifyStructParameters(builderTest.Name, test, query, builderTest.ExpectedParameters)
+	}
+}
+
+func TestQueryBuilderErrors(test *testing.T) {
+
+	builders := map[string]*QueryBuilder {
+		"NoTable": Select("id").Where(Eq("id", 1)),
+		"EmptyInsert": Insert("users"),
+		"EmptyUpdate": Update("users").Where(Eq("id", 1)),
+		"EmptyAll": Delete("users").Where(All()),
+		"EmptyAny": Select().From("users").Where(Eq("team", 1)).And(Any()),
+		"ZeroPredicate": Select().From("users").Where(Predicate{}),
+		"ZeroPredicateInGroup": Select().From("users").Where(All(Eq("team", 1), Predicate{})),
+		"InsertWhere": Insert("users").Value("name", "alice").Where(Eq("team", 2)),
+		"InsertLimit": Insert("users").Value("name", "alice").Limit(1),
+		"UpdateOrderBy": Update("users").Set("team", 2).OrderBy("name"),
+		"UpdateLimit": Update("users").Set("team", 2).Limit(1),
+		"DeleteOrderBy": Delete("users").OrderBy("name"),
+		"DeleteLimit": Delete("users").Limit(1),
+		"SelectValue": Select().From("users").Value("name", "alice"),
+		"SelectSet": Select().From("users").Set("name", "alice"),
+		"DeleteSet": Delete("users").Set("name", "alice"),
+	}
+
+	for name, builder := range builders {
+
+		_, err := builder.Text()
+		if(err == nil) {
+			test.Log("Test '", name, "': Expected building the query text to fail")
+			test.Fail()
+		}
+
+		_, err = builder.Build()
+		if(err == nil) {
+			test.Log("Test '", name, "': Expected building the query to fail")
+			test.Fail()
+		}
+	}
+
+	// an empty slice is caught when the query is validated, as with any other query.
+	query, err := Select("id").From("users").Where(In("id", []int {})).Build()
+	if(err != nil) {
+		test.Log("Unable to build query: ", err)
+		test.FailNow()
+	}
+
+	if(query.Validate() == nil) {
+		test.Log("Expected an empty IN list to fail validation")
+		test.Fail()
+	}
+}
@@ -0,0 +1,239 @@
+package namedParameterQuery
+
+import (
+	"container/list"
+	"reflect"
+	"sync"
+)
+
+/*
+	TemplateCache is a bounded, least-recently-used cache of parsed QueryTemplates,
+	keyed by query text and the options used to parse it.
+	It is safe to use from many goroutines at once.
+
+	NewNamedParameterQuery, NewNamedParameterQueryWithDialect, ParseNamedParameterQuery and
+	ParseNamedParameterQueryWithOptions all use DefaultTemplateCache, so constructing the same query
+	over and over only parses it once.
+*/
+type TemplateCache struct {
+
+	// The most templates this cache will hold before evicting the least recently used.
+	capacity int
+
+	// Entries, with the most recently used at the front. Each element holds a *cacheEntry.
+	entries *list.List
+	elements map[cacheKey]*list.Element
+
+	hits uint64
+	misses uint64
+	evictions uint64
+
+	lock sync.Mutex
+}
+
+/*
+	CacheStats is a snapshot of how well a TemplateCache is doing.
+*/
+type CacheStats struct {
+
+	// The number of lookups which found an already-parsed template.
+	Hits uint64
+
+	// The number of lookups which had to parse the query.
+	Misses uint64
+
+	// The number of templates dropped to make room for others.
+	Evictions uint64
+
+	// The number of templates currently held.
+	Size int
+
+	// The most templates the cache will hold.
+	Capacity int
+}
+
+/*
+	cacheKey identifies a parsed template. Only options which can be compared are part of the key;
+	queries parsed with a custom IdentifierPolicy, or a Dialect which can't be compared, are never cached.
+*/
+type cacheKey struct {
+	queryText string
+	dialect Dialect
+	identifiers uintptr
+	prefixes ParameterPrefix
+	emptySlices EmptySlicePolicy
+	fieldSeparator string
+	names uintptr
+	optionalFragments bool
+	slots bool
+}
+
+type cacheEntry struct {
+	key cacheKey
+	template *QueryTemplate
+	err error
+}
+
+/*
+	DefaultTemplateCache is used by the package-level constructors. It holds up to 1024 templates.
+*/
+var DefaultTemplateCache = NewTemplateCache(1024)
+
+/*
+	NewTemplateCache creates a new cache which holds up to [capacity] templates.
+	A capacity of zero or less disables caching; every lookup parses its query.
+*/
+func NewTemplateCache(capacity int) (*TemplateCache) {
+
+	var ret *TemplateCache
+
+	ret = new(TemplateCache)
+	ret.capacity = capacity
+	ret.entries = list.New()
+	ret.elements = make(map[cacheKey]*list.Element, 16)
+	return ret
+}
+
+/*
+	Parse returns the template for the given [queryText] and [options], parsing it only if it isn't already cached.
+	As with ParseQueryTemplate, a malformed query is reported as a *ParseError; the error is cached along with the query.
+*/
+func (this *TemplateCache) Parse(queryText string, options QueryOptions) (*QueryTemplate, error) {
+
+	template, err := this.parse(queryText, options)
+	if(err != nil) {
+		return nil, err
+	}
+	return template, nil
+}
+
+/*
+	parse returns the template for the given [queryText] and [options], even if the query text was malformed.
+*/
+func (this *TemplateCache) parse(queryText string, options QueryOptions) (*QueryTemplate, error) {
+
+	var key cacheKey
+	var entry *cacheEntry
+	var element *list.Element
+	var found bool
+
+	options = options.withDefaults()
+	key, found = newCacheKey(queryText, options)
+
+	if(this.capacity <= 0 || !found) {
+		this.count(&this.misses)
+		return parseQueryTemplate(queryText, options)
+	}
+
+	this.lock.Lock()
+
+	element, found = this.elements[key]
+	if(found) {
+		this.hits++
+		this.entries.MoveToFront(element)
+		entry = element.Value.(*cacheEntry)
+
+		this.lock.Unlock()
+		return entry.template, entry.err
+	}
+
+	this.misses++
+	this.lock.Unlock()
+
+	// parse outside of the lock; if two goroutines race to parse the same query, the last one wins.
+	entry = new(cacheEntry)
+	entry.key = key
+	entry.template, entry.err = parseQueryTemplate(queryText, options)
+
+	this.lock.Lock()
+	defer this.lock.Unlock()
+
+	element, found = this.elements[key]
+	if(found) {
+		this.entries.Remove(element)
+	}
+
+	this.elements[key] = this.entries.PushFront(entry)
+
+	for this.entries.Len() > this.capacity {
+
+		element = this.entries.Back()
+		this.entries.Remove(element)
+		delete(this.elements, element.Value.(*cacheEntry).key)
+		this.evictions++
+	}
+
+	return entry.template, entry.err
+}
+
+/*
+	newCacheKey returns the key for the given [queryText] and [options],
+	or false if the options can't be compared with any others.
+*/
+func newCacheKey(queryText string, options QueryOptions) (cacheKey, bool) {
+
+	var ret cacheKey
+
+	// comparing interfaces which hold uncomparable values panics.
+	if(!reflect.TypeOf(options.Dialect).Comparable()) {
+		return ret, false
+	}
+
+	ret.identifiers = reflect.ValueOf(options.Identifiers).Pointer()
+	if(!builtinIdentifierPolicies[ret.identifiers]) {
+		return ret, false
+	}
+
+	ret.names = reflect.ValueOf(options.Names).Pointer()
+	if(!builtinNameMappers[ret.names]) {
+		return ret, false
+	}
+
+	ret.queryText = queryText
+	ret.dialect = options.Dialect
+	ret.prefixes = options.Prefixes
+	ret.emptySlices = options.EmptySlices
+	ret.fieldSeparator = options.FieldSeparator
+	ret.optionalFragments = options.OptionalFragments
+	ret.slots = options.Slots
+	return ret, true
+}
+
+func (this *TemplateCache) count(counter *uint64) {
+
+	this.lock.Lock()
+	*counter++
+	this.lock.Unlock()
+}
+
+/*
+	Stats returns the current statistics of this cache.
+*/
+func (this *TemplateCache) Stats() (CacheStats) {
+
+	this.lock.Lock()
+	defer this.lock.Unlock()
+
+	return CacheStats {
+		Hits: this.hits,
+		Misses: this.misses,
+		Evictions: this.evictions,
+		Size: this.entries.Len(),
+		Capacity: this.capacity,
+	}
+}
+
+/*
+	Clear removes every template from this cache, and resets its statistics.
+*/
+func (this *TemplateCache) Clear() {
+
+	this.lock.Lock()
+	defer this.lock.Unlock()
+
+	this.entries.Init()
+	this.elements = make(map[cacheKey]*list.Element, 16)
+	this.hits = 0
+	this.misses = 0
+	this.evictions = 0
+}
@@ -0,0 +1,91 @@
+package namedParameterQuery
+
+import (
+	"testing"
+)
+
+func TestTemplateCacheHits(test *testing.T) {
+
+	cache := NewTemplateCache(8)
+
+	first, _ := cache.Parse("SELECT * FROM table WHERE col1 = :foo", QueryOptions{})
+	second, _ := cache.Parse("SELECT * FROM table WHERE col1 = :foo", QueryOptions { Dialect: MySQLDialect })
+	third, _ := cache.Parse("SELECT * FROM table WHERE col1 = :foo", QueryOptions { Dialect: PostgresDialect })
+
+	if(first != second) {
+		test.Log("Expected the same query and options to share a template")
+		test.Fail()
+	}
+
+	if(first == third || third.GetParsedQuery() != "SELECT * FROM table WHERE col1 = $1") {
+		test.Log("Expected a different dialect to parse a different template")
+		test.Fail()
+	}
+
+	verifyCacheStats(test, cache, CacheStats { Hits: 1, Misses: 2, Size: 2, Capacity: 8 })
+}
+
+func TestTemplateCacheEviction(test *testing.T) {
+
+	cache := NewTemplateCache(2)
+
+	first, _ := cache.Parse("SELECT :a", QueryOptions{})
+	cache.Parse("SELECT :b", QueryOptions{})
+
+	// touch "a", so that "b" is the least recently used.
+	cache.Parse("SELECT :a", QueryOptions{})
+	cache.Parse("SELECT :c", QueryOptions{})
+
+	again, _ := cache.Parse("SELECT :a", QueryOptions{})
+	if(again != first) {
+		test.Log("Expected the most recently used template to survive eviction")
+		test.Fail()
+	}
+
+	cache.Parse("SELECT :b", QueryOptions{})
+	verifyCacheStats(test, cache, CacheStats { Hits: 2, Misses: 4, Evictions: 2, Size: 2, Capacity: 2 })
+}
+
+func TestTemplateCacheUncachable(test *testing.T) {
+
+	cache := NewTemplateCache(8)
+	policy := func(character rune) bool {
+		return character == '-' || DefaultIdentifiers(character)
+	}
+
+	first, _ := cache.Parse("SELECT :a-b", QueryOptions { Identifiers: policy })
+	second, _ := cache.Parse("SELECT :a-b", QueryOptions { Identifiers: policy })
+
+	if(first == second) {
+		test.Log("Expected custom identifier policies not to be cached")
+		test.Fail()
+	}
+
+	verifyCacheStats(test, cache, CacheStats { Misses: 2, Capacity: 8 })
+}
+
+func TestTemplateCacheErrors(test *testing.T) {
+
+	cache := NewTemplateCache(8)
+
+	for i := 0; i < 2; i++ {
+
+		template, err := cache.Parse("SELECT 'oops", QueryOptions{})
+		if(template != nil || err == nil) {
+			test.Log("Expected a malformed query to fail every time")
+			test.Fail()
+		}
+	}
+
+	cache.Clear()
+	verifyCacheStats(test, cache, CacheStats { Capacity: 8 })
+}
+
+func verifyCacheStats(test *testing.T, cache *TemplateCache, expected CacheStats) {
+
+	actual := cache.Stats()
+	if(actual != expected) {
+		test.Logf("Expected cache stats %+v, got %+v", expected, actual)
+		test.Fail()
+	}
+}
@@ -0,0 +1,179 @@
+package namedParameterQuery
+
+import (
+	"errors"
+	"sort"
+	"strings"
+)
+
+/*
+	Compose returns a new template in which every "{{slot}}" of the given name is replaced by the given [fragment].
+	Slots are only recognized in templates parsed with the Slots option.
+	The fragment's parameters become parameters of the new template, so the result can be bound like any other.
+	This template and the fragment are left as they are, so a fragment can be composed into any number of queries.
+
+	If any parameter name appears in both this template and the fragment, an error is returned,
+	since it's unlikely that both were written to expect the same value; see ComposePrefixed to avoid this.
+	The new template is parsed with this template's options, and any slots left in either are kept for later.
+	The fragment's parameter names are mapped by this template's NameMapper, but its parameters must be written
+	with the same prefixes as this template's, or an error is returned.
+*/
+func (this *QueryTemplate) Compose(slot string, fragment *QueryTemplate) (*QueryTemplate, error) {
+	return this.compose(slot, fragment, "")
+}
+
+/*
+	ComposePrefixed is like Compose, but renames every parameter of the [fragment] with the slot's name,
+	joined by this template's FieldSeparator; e.g. ":status" composed into "{{filter}}" becomes "filter.status".
+	This matches the naming of nested struct fields, so a struct with a Filter field can set all the fragment's parameters.
+*/
+func (this *QueryTemplate) ComposePrefixed(slot string, fragment *QueryTemplate) (*QueryTemplate, error) {
+	return this.compose(slot, fragment, slot + this.options.FieldSeparator)
+}
+
+/*
+	JoinTemplates returns a new template made of the given [fragments], one after the other, with the given [separator]
+	between each; e.g. " UNION ALL " or " AND ". The new template uses the options of the first fragment,
+	so the rest are treated as fragments are by Compose.
+	If any parameter name appears in more than one fragment, an error is returned.
+*/
+func JoinTemplates(separator string, fragments ...*QueryTemplate) (*QueryTemplate, error) {
+
+	var segments []segment
+	var inserted []segment
+	var err error
+
+	if(len(fragments) == 0) {
+		return nil, errors.New("Unable to join queries: no queries given")
+	}
+
+	for index, fragment := range fragments {
+
+		inserted, err = adoptSegments(fragment, fragments[0].options, "")
+		if(err != nil) {
+			return nil, err
+		}
+
+		err = checkCollisions(segments, inserted)
+		if(err != nil) {
+			return nil, err
+		}
+
+		if(index > 0) {
+			segments = append(segments, segment { kind: textSegment, text: separator })
+		}
+		segments = append(segments, inserted...)
+	}
+
+	return newComposedTemplate(segments, fragments[0].options), nil
+}
+
+func (this *QueryTemplate) compose(slot string, fragment *QueryTemplate, prefix string) (*QueryTemplate, error) {
+
+	var segments []segment
+	var inserted []segment
+	var found bool
+
+	inserted, err := adoptSegments(fragment, this.options, prefix)
+	if(err != nil) {
+		return nil, err
+	}
+
+	err = checkCollisions(this.segments, inserted)
+	if(err != nil) {
+		return nil, err
+	}
+
+	segments = make([]segment, 0, len(this.segments) + len(inserted))
+
+	for _, piece := range this.segments {
+
+		if(piece.kind == slotSegment && piece.name == slot) {
+			segments = append(segments, inserted...)
+			found = true
+			continue
+		}
+		segments = append(segments, piece)
+	}
+
+	if(!found) {
+		return nil, errors.New("Unable to compose query: query has no slot named " + slot)
+	}
+
+	return newComposedTemplate(segments, this.options), nil
+}
+
+/*
+	adoptSegments returns a copy of the segments of the given [fragment], ready to become part of a template
+	with the given [options]; every parameter (and identifier parameter) is given the [prefix], and mapped by
+	the options' NameMapper. If the fragment's parameters are written with other prefixes, the composed query
+	couldn't be parsed again, so an error is returned.
+*/
+func adoptSegments(fragment *QueryTemplate, options QueryOptions, prefix string) ([]segment, error) {
+
+	var ret []segment
+
+	if(fragment.options.Prefixes != options.Prefixes) {
+		return nil, errors.New("Unable to compose query: queries must use the same parameter prefixes")
+	}
+
+	ret = make([]segment, len(fragment.segments))
+	copy(ret, fragment.segments)
+
+	for index := range ret {
+		if(ret[index].kind == parameterSegment || ret[index].kind == identifierSegment) {
+			ret[index].name = options.Names(prefix + ret[index].name)
+		}
+	}
+	return ret, nil
+}
+
+/*
+	newComposedTemplate builds a new template from the given [segments], taken from other templates.
+	Its original query is the text of every segment, one after the other.
+*/
+func newComposedTemplate(segments []segment, options QueryOptions) (*QueryTemplate) {
+
+	var ret *QueryTemplate
+
+	ret = new(QueryTemplate)
+	ret.options = options
+	ret.segments = segments
+	ret.originalQuery = joinSegments(ret.segments)
+	ret.build()
+	return ret
+}
+
+/*
+	checkCollisions returns an error listing every parameter (or identifier parameter) name
+	which appears in both the [existing] and [added] segments.
+*/
+func checkCollisions(existing []segment, added []segment) (error) {
+
+	var names map[segmentKind]map[string]bool
+	var collisions []string
+
+	names = map[segmentKind]map[string]bool {
+		parameterSegment: make(map[string]bool),
+		identifierSegment: make(map[string]bool),
+	}
+
+	for _, piece := range existing {
+		if(names[piece.kind] != nil) {
+			names[piece.kind][piece.name] = true
+		}
+	}
+
+	for _, piece := range added {
+		if(names[piece.kind][piece.name]) {
+			collisions = appendUnique(collisions, piece.name)
+		}
+	}
+
+	if(len(collisions) == 0) {
+		return nil
+	}
+
+	sort.Strings(collisions)
+	return errors.New("Unable to compose query: parameters appear in more than one query: " + strings.Join(collisions, ", "))
+}
@@ -0,0 +1,152 @@
+package namedParameterQuery
+
+import (
+	"testing"
+)
+
+func TestCompose(test *testing.T) {
+
+	outer := MustParseQueryTemplate("SELECT * FROM users WHERE team = :team AND id IN ({{active}})", QueryOptions { Dialect: PostgresDialect, Slots: true })
+	fragment := MustParseQueryTemplate("SELECT user_id FROM logins WHERE since > :since", QueryOptions{})
+
+	composed, err := outer.Compose("active", fragment)
+	if(err != nil) {
+		test.Log("Unable to compose query: ", err)
+		test.FailNow()
+	}
+
+	if(composed.GetOriginalQuery() != "SELECT * FROM users WHERE team = :team AND id IN (SELECT user_id FROM logins WHERE since > :since)") {
+		test.Log("Unexpected composed query: ", composed.GetOriginalQuery())
+		test.Fail()
+	}
+
+	if(composed.GetParsedQuery() != "SELECT * FROM users WHERE team = $1 AND id IN (SELECT user_id FROM logins WHERE since > $2)") {
+		test.Log("Unexpected composed parsed query: ", composed.GetParsedQuery())
+		test.Fail()
+	}
+
+	binding := composed.Bind()
+	binding.SetValuesFromMap(map[string]interface{} { "team": 1, "since": "2020-01-01" })
+	verifyBindingParameters("Compose", test, binding, []interface{} { 1, "2020-01-01" })
+
+	occurrences := composed.Occurrences("since")
+	if(len(occurrences) != 1 || occurrences[0].Offset != 91) {
+		test.Log("Expected occurrences to refer to the composed query: ", occurrences)
+		test.Fail()
+	}
+
+	// the original templates must be untouched.
+	if(outer.GetParsedQuery() != "SELECT * FROM users WHERE team = $1 AND id IN ({{active}})") {
+		test.Log("Composing changed the outer template: ", outer.GetParsedQuery())
+		test.Fail()
+	}
+}
+
+func TestComposeCollisions(test *testing.T) {
+
+	outer := MustParseQueryTemplate("SELECT * FROM users WHERE status = :status AND {{filter}}", QueryOptions { Slots: true })
+	fragment := MustParseQueryTemplate("status = :status AND age > :age", QueryOptions{})
+
+	_, err := outer.Compose("filter", fragment)
+	if(err == nil || err.Error() != "Unable to compose query: parameters appear in more than one query: status") {
+		test.Log("Expected a parameter collision to fail: ", err)
+		test.Fail()
+	}
+
+	_, err = outer.Compose("missing", fragment)
+	if(err == nil) {
+		test.Log("Expected composing into a missing slot to fail")
+		test.Fail()
+	}
+
+	composed, err := outer.ComposePrefixed("filter", fragment)
+	if(err != nil) {
+		test.Log("Unable to compose prefixed query: ", err)
+		test.FailNow()
+	}
+
+	if(!equalNames(composed.ParameterNames(), []string { "status", "filter.status", "filter.age" })) {
+		test.Log("Unexpected prefixed parameter names: ", composed.ParameterNames())
+		test.Fail()
+	}
+}
+
+func TestJoinTemplates(test *testing.T) {
+
+	first := MustParseQueryTemplate("SELECT id FROM users WHERE name = :name", QueryOptions { Dialect: OracleDialect })
+	second := MustParseQueryTemplate("SELECT id FROM admins WHERE role = :role", QueryOptions{})
+
+	joined, err := JoinTemplates(" UNION ALL ", first, second)
+	if(err != nil) {
+		test.Log("Unable to join queries: ", err)
+		test.FailNow()
+	}
+
+	if(joined.GetParsedQuery() != "SELECT id FROM users WHERE name = :1 UNION ALL SELECT id FROM admins WHERE role = :2") {
+		test.Log("Unexpected joined query: ", joined.GetParsedQuery())
+		test.Fail()
+	}
+
+	_, err = JoinTemplates(" UNION ALL ", first, first)
+	if(err == nil) {
+		test.Log("Expected joining queries with the same parameters to fail")
+		test.Fail()
+	}
+}
+
+func TestUnfilledSlots(test *testing.T) {
+
+	// without the Slots option, braces are plain SQL.
+	plain := MustParseQueryTemplate("SELECT '{{' || name || '}}', {{x}} FROM users WHERE id = :id", QueryOptions{})
+	if(plain.GetParsedQuery() != "SELECT '{{' || name || '}}', {{x}} FROM users WHERE id = ?") {
+		test.Log("Expected braces to be left alone without the Slots option: ", plain.GetParsedQuery())
+		test.Fail()
+	}
+
+	binding := MustParseQueryTemplate("SELECT * FROM users WHERE id = :id AND {{filter}}", QueryOptions { Slots: true }).Bind()
+	binding.SetValue("id", 1)
+
+	bindingError, ok := binding.Validate().(*BindingError)
+	if(!ok || !equalNames(bindingError.Unfilled, []string { "filter" })) {
+		test.Log("Expected an unfilled slot to be reported by Validate: ", bindingError)
+		test.Fail()
+	}
+}
+
+/*
+	Ensures that fragments parsed with other options become part of the outer query as if they'd been written there.
+*/
+func TestComposeMismatchedOptions(test *testing.T) {
+
+	outer := MustParseQueryTemplate("SELECT * FROM users WHERE id IN ({{active}})",
+		QueryOptions { Dialect: PostgresDialect, Names: CaseInsensitiveNames, Slots: true })
+	fragment := MustParseQueryTemplate("SELECT user_id FROM logins WHERE since > :Since", QueryOptions{})
+
+	composed, err := outer.Compose("active", fragment)
+	if(err != nil) {
+		test.Log("Unable to compose query: ", err)
+		test.FailNow()
+	}
+
+	binding := composed.Bind()
+	binding.SetValue("SINCE", "2020-01-01")
+
+	if(binding.Validate() != nil) {
+		test.Log("Expected the fragment's parameter to be mapped by the outer query's NameMapper: ", binding.Validate())
+		test.Fail()
+	}
+
+	prefixed := MustParseQueryTemplate("user_id = @x", QueryOptions { Prefixes: AtPrefix })
+
+	_, err = outer.Compose("active", prefixed)
+	if(err == nil) {
+		test.Log("Expected composing a fragment with other parameter prefixes to fail")
+		test.Fail()
+	}
+
+	_, err = JoinTemplates(" AND ", fragment, prefixed)
+	if(err == nil) {
+		test.Log("Expected joining queries with other parameter prefixes to fail")
+		test.Fail()
+	}
+}
@@ -0,0 +1,300 @@
+package namedParameterQuery
+
+import (
+	"context"
+	"database/sql"
+)
+
+/*
+	namedExecutor is implemented by *sql.DB, *sql.Tx and *sql.Conn; anything which can run a positional query.
+*/
+type namedExecutor interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+/*
+	NamedDB wraps a *sql.DB, adding methods which take a query with named parameters,
+	and a map or struct holding their values. Every method of the wrapped *sql.DB is still available.
+
+		db := NewNamedDB(connection, QueryOptions { Dialect: PostgresDialect })
+		rows, err := db.QueryNamed("SELECT * FROM users WHERE id = :id", map[string]interface{} { "id": 5 })
+
+	Queries are parsed through DefaultTemplateCache, so running the same query text again doesn't re-parse it.
+*/
+type NamedDB struct {
+	*sql.DB
+	options QueryOptions
+}
+
+/*
+	NamedTx wraps a *sql.Tx in the same way that NamedDB wraps a *sql.DB.
+*/
+type NamedTx struct {
+	*sql.Tx
+	options QueryOptions
+}
+
+/*
+	NamedConn wraps a *sql.Conn in the same way that NamedDB wraps a *sql.DB.
+*/
+type NamedConn struct {
+	*sql.Conn
+	options QueryOptions
+}
+
+/*
+	NamedRow is the result of QueryRowNamed. It behaves like a *sql.Row,
+	but can also carry an error from parsing or binding the query.
+*/
+type NamedRow struct {
+	row *sql.Row
+	err error
+}
+
+/*
+	NewNamedDB wraps the given [db], parsing queries according to the given [options].
+*/
+func NewNamedDB(db *sql.DB, options QueryOptions) (*NamedDB) {
+	return &NamedDB { DB: db, options: options }
+}
+
+/*
+	NewNamedTx wraps the given [tx], parsing queries according to the given [options].
+*/
+func NewNamedTx(tx *sql.Tx, options QueryOptions) (*NamedTx) {
+	return &NamedTx { Tx: tx, options: options }
+}
+
+/*
+	NewNamedConn wraps the given [conn], parsing queries according to the given [options].
+*/
+func NewNamedConn(conn *sql.Conn, options QueryOptions) (*NamedConn) {
+	return &NamedConn { Conn: conn, options: options }
+}
+
+/*
+	BeginNamed starts a transaction, in the same way as BeginTx, and wraps it with this NamedDB's options.
+*/
+func (this *NamedDB) BeginNamed(ctx context.Context, txOptions *sql.TxOptions) (*NamedTx, error) {
+
+	tx, err := this.DB.BeginTx(ctx, txOptions)
+	if(err != nil) {
+		return nil, err
+	}
+	return NewNamedTx(tx, this.options), nil
+}
+
+/*
+	ConnNamed reserves a single connection, in the same way as Conn, and wraps it with this NamedDB's options.
+*/
+func (this *NamedDB) ConnNamed(ctx context.Context) (*NamedConn, error) {
+
+	conn, err := this.DB.Conn(ctx)
+	if(err != nil) {
+		return nil, err
+	}
+	return NewNamedConn(conn, this.options), nil
+}
+
+/*
+	BeginNamed starts a transaction on this connection, and wraps it with this NamedConn's options.
+*/
+func (this *NamedConn) BeginNamed(ctx context.Context, txOptions *sql.TxOptions) (*NamedTx, error) {
+
+	tx, err := this.Conn.BeginTx(ctx, txOptions)
+	if(err != nil) {
+		return nil, err
+	}
+	return NewNamedTx(tx, this.options), nil
+}
+
+/*
+	QueryNamed runs the given [queryText], taking the values of its named parameters from [parameters],
+	which may be a map or a struct, or a pointer to either (see SetValues).
+	Every named parameter must be given a value; see Validate.
+*/
+func (this *NamedDB) QueryNamed(queryText string, parameters interface{}) (*sql.Rows, error) {
+	return queryNamed(context.Background(), this.DB, this.options, queryText, parameters)
+}
+
+// QueryNamedContext is like QueryNamed, but uses the given [ctx].
+func (this *NamedDB) QueryNamedContext(ctx context.Context, queryText string, parameters interface{}) (*sql.Rows, error) {
+	return queryNamed(ctx, this.DB, this.options, queryText, parameters)
+}
+
+/*
+	QueryRowNamed runs the given [queryText], which is expected to return at most one row,
+	taking the values of its named parameters from [parameters] as QueryNamed does.
+	Any error is deferred until the row is scanned.
+*/
+func (this *NamedDB) QueryRowNamed(queryText string, parameters interface{}) (*NamedRow) {
+	return queryRowNamed(context.Background(), this.DB, this.options, queryText, parameters)
+}
+
+// QueryRowNamedContext is like QueryRowNamed, but uses the given [ctx].
+func (this *NamedDB) QueryRowNamedContext(ctx context.Context, queryText string, parameters interface{}) (*NamedRow) {
+	return queryRowNamed(ctx, this.DB, this.options, queryText, parameters)
+}
+
+/*
+	ExecNamed runs the given [queryText] without returning any rows,
+	taking the values of its named parameters from [parameters] as QueryNamed does.
+*/
+func (this *NamedDB) ExecNamed(queryText string, parameters interface{}) (sql.Result, error) {
+	return execNamed(context.Background(), this.DB, this.options, queryText, parameters)
+}
+
+// ExecNamedContext is like ExecNamed, but uses the given [ctx].
+func (this *NamedDB) ExecNamedContext(ctx context.Context, queryText string, parameters interface{}) (sql.Result, error) {
+	return execNamed(ctx, this.DB, this.options, queryText, parameters)
+}
+
+// QueryNamed runs the given [queryText] within this transaction. See NamedDB.QueryNamed.
+func (this *NamedTx) QueryNamed(queryText string, parameters interface{}) (*sql.Rows, error) {
+	return queryNamed(context.Background(), this.Tx, this.options, queryText, parameters)
+}
+
+// QueryNamedContext is like QueryNamed, but uses the given [ctx].
+func (this *NamedTx) QueryNamedContext(ctx context.Context, queryText string, parameters interface{}) (*sql.Rows, error) {
+	return queryNamed(ctx, this.Tx, this.options, queryText, parameters)
+}
+
+// QueryRowNamed runs the given [queryText] within this transaction. See NamedDB.QueryRowNamed.
+func (this *NamedTx) QueryRowNamed(queryText string, parameters interface{}) (*NamedRow) {
+	return queryRowNamed(context.Background(), this.Tx, this.options, queryText, parameters)
+}
+
+// QueryRowNamedContext is like QueryRowNamed, but uses the given [ctx].
+func (this *NamedTx) QueryRowNamedContext(ctx context.Context, queryText string, parameters interface{}) (*NamedRow) {
+	return queryRowNamed(ctx, this.Tx, this.options, queryText, parameters)
+}
+
+// ExecNamed runs the given [queryText] within this transaction. See NamedDB.ExecNamed.
+func (this *NamedTx) ExecNamed(queryText string, parameters interface{}) (sql.Result, error) {
+	return execNamed(context.Background(), this.Tx, this.options, queryText, parameters)
+}
+
+// ExecNamedContext is like ExecNamed, but uses the given [ctx].
+func (this *NamedTx) ExecNamedContext(ctx context.Context, queryText string, parameters interface{}) (sql.Result, error) {
+	return execNamed(ctx, this.Tx, this.options, queryText, parameters)
+}
+
+// QueryNamed runs the given [queryText] on this connection. See NamedDB.QueryNamed.
+func (this *NamedConn) QueryNamed(queryText string, parameters interface{}) (*sql.Rows, error) {
+	return queryNamed(context.Background(), this.Conn, this.options, queryText, parameters)
+}
+
+// QueryNamedContext is like QueryNamed, but uses the given [ctx].
+func (this *NamedConn) QueryNamedContext(ctx context.Context, queryText string, parameters interface{}) (*sql.Rows, error) {
+	return queryNamed(ctx, this.Conn, this.options, queryText, parameters)
+}
+
+// QueryRowNamed runs the given [queryText] on this connection. See NamedDB.QueryRowNamed.
+func (this *NamedConn) QueryRowNamed(queryText string, parameters interface{}) (*NamedRow) {
+	return queryRowNamed(context.Background(), this.Conn, this.options, queryText, parameters)
+}
+
+// QueryRowNamedContext is like QueryRowNamed, but uses the given [ctx].
+func (this *NamedConn) QueryRowNamedContext(ctx context.Context, queryText string, parameters interface{}) (*NamedRow) {
+	return queryRowNamed(ctx, this.Conn, this.options, queryText, parameters)
+}
+
+// ExecNamed runs the given [queryText] on this connection. See NamedDB.ExecNamed.
+func (this *NamedConn) ExecNamed(queryText string, parameters interface{}) (sql.Result, error) {
+	return execNamed(context.Background(), this.Conn, this.options, queryText, parameters)
+}
+
+// ExecNamedContext is like ExecNamed, but uses the given [ctx].
+func (this *NamedConn) ExecNamedContext(ctx context.Context, queryText string, parameters interface{}) (sql.Result, error) {
+	return execNamed(ctx, this.Conn, this.options, queryText, parameters)
+}
+
+/*
+	Scan copies the columns of the row into [dest], as *sql.Row's Scan does.
+	If the query could not be parsed or bound, that error is returned instead.
+*/
+func (this *NamedRow) Scan(dest ...interface{}) (error) {
+
+	if(this.err != nil) {
+		return this.err
+	}
+	return this.row.Scan(dest...)
+}
+
+/*
+	Err returns any error from parsing, binding, or running the query, without scanning the row.
+*/
+func (this *NamedRow) Err() (error) {
+
+	if(this.err != nil) {
+		return this.err
+	}
+	return this.row.Err()
+}
+
+func queryNamed(ctx context.Context, executor namedExecutor, options QueryOptions, queryText string, parameters interface{}) (*sql.Rows, error) {
+
+	query, args, err := bindNamed(options, queryText, parameters)
+	if(err != nil) {
+		return nil, err
+	}
+	return executor.QueryContext(ctx, query, args...)
+}
+
+func queryRowNamed(ctx context.Context, executor namedExecutor, options QueryOptions, queryText string, parameters interface{}) (*NamedRow) {
+
+	query, args, err := bindNamed(options, queryText, parameters)
+	if(err != nil) {
+		return &NamedRow { err: err }
+	}
+	return &NamedRow { row: executor.QueryRowContext(ctx, query, args...) }
+}
+
+func execNamed(ctx context.Context, executor namedExecutor, options QueryOptions, queryText string, parameters interface{}) (sql.Result, error) {
+
+	query, args, err := bindNamed(options, queryText, parameters)
+	if(err != nil) {
+		return nil, err
+	}
+	return executor.ExecContext(ctx, query, args...)
+}
+
+/*
+	bindNamed parses the given [queryText], binds the given [parameters] to it,
+	and returns the positional query and its arguments.
+*/
+func bindNamed(options QueryOptions, queryText string, parameters interface{}) (string, []interface{}, error) {
+
+	template, err := DefaultTemplateCache.Parse(queryText, options)
+	if(err != nil) {
+		return "", nil, err
+	}
+
+	binding, err := bindTemplate(template, parameters)
+	if(err != nil) {
+		return "", nil, err
+	}
+	return binding.GetParsedQuery(), binding.GetParsedParameters(), nil
+}
+
+/*
+	bindTemplate binds the given [parameters] to a new binding of the given [template],
+	returning an error if any parameter is left unbound.
+*/
+func bindTemplate(template *QueryTemplate, parameters interface{}) (*Binding, error) {
+
+	binding := template.Bind()
+
+	err := binding.SetValues(parameters)
+	if(err != nil) {
+		return nil, err
+	}
+
+	err = binding.Validate()
+	if(err != nil) {
+		return nil, err
+	}
+	return binding, nil
+}
@@ -0,0 +1,125 @@
+package namedParameterQuery
+
+import (
+	"context"
+	"database/sql/driver"
+	"testing"
+)
+
+type databaseTestParameters struct {
+	Foo string `sqlParameterName:"foo"`
+	Bar int `sqlParameterName:"bar"`
+}
+
+func TestNamedDB(test *testing.T) {
+
+	var name string
+
+	connection, fake := openFakeDB([]string { "name" }, []driver.Value { "alice" })
+	db := NewNamedDB(connection, QueryOptions { Dialect: PostgresDialect })
+	defer db.Close()
+
+	_, err := db.ExecNamed("UPDATE table SET col1 = :foo WHERE col2 = :bar OR col3 = :foo", map[string]interface{} { "foo": "x", "bar": 2 })
+	verifyFakeStatement("ExecNamed", test, err, fake, "UPDATE table SET col1 = $1 WHERE col2 = $2 OR col3 = $1", "x", int64(2))
+
+	rows, err := db.QueryNamedContext(context.Background(), "SELECT name FROM table WHERE id IN (:ids)", map[string]interface{} { "ids": []int { 1, 2 } })
+	verifyFakeStatement("QueryNamed", test, err, fake, "SELECT name FROM table WHERE id IN ($1, $2)", int64(1), int64(2))
+	if(err == nil) {
+		rows.Close()
+	}
+
+	err = db.QueryRowNamed("SELECT name FROM table WHERE col1 = :foo AND col2 = :bar", databaseTestParameters { Foo: "y", Bar: 3 }).Scan(&name)
+	verifyFakeStatement("QueryRowNamed", test, err, fake, "SELECT name FROM table WHERE col1 = $1 AND col2 = $2", "y", int64(3))
+
+	if(name != "alice") {
+		test.Log("Expected the row to be scanned, got: ", name)
+		test.Fail()
+	}
+}
+
+func TestNamedDBErrors(test *testing.T) {
+
+	connection, fake := openFakeDB(nil)
+	db := NewNamedDB(connection, QueryOptions{})
+	defer db.Close()
+
+	_, err := db.ExecNamed("UPDATE table SET col1 = 'oops", nil)
+	if(err == nil) {
+		test.Log("Expected a malformed query to fail")
+		test.Fail()
+	}
+
+	_, err = db.QueryNamed("SELECT * FROM table WHERE col1 = :foo", map[string]interface{}{})
+	if(err == nil) {
+		test.Log("Expected a query with an unbound parameter to fail")
+		test.Fail()
+	}
+
+	err = db.QueryRowNamed("SELECT * FROM table WHERE col1 = :foo", 5).Err()
+	if(err == nil) {
+		test.Log("Expected a query with unusable parameters to fail")
+		test.Fail()
+	}
+
+	if(len(fake.statements) != 0) {
+		test.Log("Expected no failed query to reach the driver")
+		test.Fail()
+	}
+}
+
+func TestNamedTxAndConn(test *testing.T) {
+
+	ctx := context.Background()
+	connection, fake := openFakeDB(nil)
+	db := NewNamedDB(connection, QueryOptions{})
+	defer db.Close()
+
+	tx, err := db.BeginNamed(ctx, nil)
+	if(err != nil) {
+		test.Log("Unable to begin transaction: ", err)
+		test.FailNow()
+	}
+
+	_, err = tx.ExecNamed("DELETE FROM table WHERE col1 = :foo", databaseTestParameters { Foo: "z" })
+	verifyFakeStatement("NamedTx", test, err, fake, "DELETE FROM table WHERE col1 = ?", "z")
+	tx.Rollback()
+
+	conn, err := db.ConnNamed(ctx)
+	if(err != nil) {
+		test.Log("Unable to reserve connection: ", err)
+		test.FailNow()
+	}
+	defer conn.Close()
+
+	_, err = conn.ExecNamedContext(ctx, "DELETE FROM table WHERE col2 = :bar", map[string]interface{} { "bar": 7 })
+	verifyFakeStatement("NamedConn", test, err, fake, "DELETE FROM table WHERE col2 = ?", int64(7))
+}
+
+func verifyFakeStatement(testName string, test *testing.T, err error, fake *fakeDriver, expectedQuery string, expectedArgs ...driver.Value) {
+
+	if(err != nil) {
+		test.Log("Test ", testName, ": Unexpected error: ", err)
+		test.Fail()
+		return
+	}
+
+	statement := fake.last()
+
+	if(statement.query != expectedQuery) {
+		test.Log("Test ", testName, ": Expected query '", expectedQuery, "', got '", statement.query, "'")
+		test.Fail()
+	}
+
+	if(len(statement.args) != len(expectedArgs)) {
+		test.Log("Test ", testName, ": Expected ", len(expectedArgs), " arguments, got ", statement.args)
+		test.Fail()
+		return
+	}
+
+	for index, arg := range statement.args {
+		if(arg != expectedArgs[index]) {
+			test.Log("Test ", testName, ": Argument ", index, " was ", arg, ", expected ", expectedArgs[index])
+			test.Fail()
+		}
+	}
+}
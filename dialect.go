@@ -0,0 +1,191 @@
+package namedParameterQuery
+
+import (
+	"strconv"
+	"strings"
+)
+
+/*
+	Dialect describes how a particular database (or driver) expects positional parameters to be written.
+	MySQL and SQLite drivers use a bare "?" for every parameter, whereas Postgres, Oracle, and SQL Server
+	drivers expect each placeholder to carry its own index, e.g. "$1", ":1", or "@p1".
+
+	The built-in dialects should cover most drivers, but any type which implements this interface
+	can be given to NewNamedParameterQueryWithDialect.
+*/
+type Dialect interface {
+
+	/*
+		Placeholder returns the text which should be written into the parsed query
+		for the positional parameter at the given zero-based [index].
+	*/
+	Placeholder(index int) string
+
+	/*
+		ReusesPlaceholders returns true if the placeholders of this dialect are numbered,
+		meaning that a named parameter which appears more than once in a query can refer
+		to the same positional parameter every time, rather than being passed once per occurrence.
+	*/
+	ReusesPlaceholders() bool
+
+	/*
+		Syntax returns the lexical rules of this dialect, which determine which parts of a query
+		are strings, quoted identifiers, or comments, and therefore cannot contain named parameters.
+	*/
+	Syntax() SyntaxRules
+
+	/*
+		MaxParameters returns the most positional parameters which a single query may have in this dialect,
+		or zero if there is no known limit. See BatchInsert.
+	*/
+	MaxParameters() int
+
+	/*
+		QuoteIdentifier returns the given identifier [name] quoted for this dialect, so that it can be written
+		into a query as a table or column name whatever characters it contains. See Binding.SetIdentifier.
+	*/
+	QuoteIdentifier(name string) string
+}
+
+/*
+	SyntaxRules describes the lexical quirks of a dialect that matter when finding named parameters.
+	Single-quoted strings, double-quoted identifiers, "--" line comments and block comments
+	are understood for every dialect; these rules enable the rest.
+*/
+type SyntaxRules struct {
+
+	// Backslashes escape the following character inside quoted strings, as in MySQL.
+	BackslashEscapes bool
+
+	// Block comments may be nested inside each other, as in Postgres.
+	NestedComments bool
+
+	// Strings may be dollar-quoted, e.g. $$text$$ or $tag$text$tag$, as in Postgres.
+	DollarQuotes bool
+
+	// Strings prefixed with E, e.g. E'it\'s', treat backslashes as escapes, as in Postgres.
+	EscapeStrings bool
+
+	// Identifiers may be quoted with backticks, as in MySQL and SQLite.
+	BacktickIdentifiers bool
+
+	// Identifiers may be quoted with square brackets, as in SQL Server and SQLite.
+	BracketIdentifiers bool
+
+	// A "#" starts a comment which runs to the end of the line, as in MySQL.
+	HashComments bool
+
+	// Arrays may be sliced with a colon between the bounds, e.g. arr[1:2], as in Postgres.
+	ArraySlices bool
+}
+
+/*
+	standardDialect implements Dialect for the common case where every placeholder
+	is a fixed prefix, optionally followed by a one-based parameter number.
+*/
+type standardDialect struct {
+	name string
+	prefix string
+	numbered bool
+	syntax SyntaxRules
+	maxParameters int
+
+	// The characters which open and close a quoted identifier, e.g. "[]".
+	quotes string
+}
+
+var (
+	// MySQLDialect writes every parameter as "?". This is the default dialect.
+	MySQLDialect Dialect = &standardDialect {
+		name: "mysql",
+		quotes: "``",
+		prefix: "?",
+		syntax: SyntaxRules {
+			BackslashEscapes: true,
+			BacktickIdentifiers: true,
+			HashComments: true,
+		},
+		maxParameters: 65535,
+	}
+
+	// SQLiteDialect writes every parameter as "?".
+	SQLiteDialect Dialect = &standardDialect {
+		name: "sqlite",
+		quotes: `""`,
+		prefix: "?",
+		syntax: SyntaxRules {
+			BacktickIdentifiers: true,
+			BracketIdentifiers: true,
+		},
+		maxParameters: 32766,
+	}
+
+	// PostgresDialect writes parameters as "$1", "$2", etc, as used by lib/pq and pgx.
+	PostgresDialect Dialect = &standardDialect {
+		name: "postgres",
+		quotes: `""`,
+		prefix: "$",
+		numbered: true,
+		syntax: SyntaxRules {
+			NestedComments: true,
+			DollarQuotes: true,
+			EscapeStrings: true,
+			ArraySlices: true,
+		},
+		maxParameters: 65535,
+	}
+
+	// OracleDialect writes parameters as ":1", ":2", etc.
+	OracleDialect Dialect = &standardDialect {
+		name: "oracle",
+		quotes: `""`,
+		prefix: ":",
+		numbered: true,
+		maxParameters: 65535,
+	}
+
+	// SQLServerDialect writes parameters as "@p1", "@p2", etc.
+	SQLServerDialect Dialect = &standardDialect {
+		name: "sqlserver",
+		quotes: "[]",
+		prefix: "@p",
+		numbered: true,
+		syntax: SyntaxRules {
+			BracketIdentifiers: true,
+		},
+		maxParameters: 2100,
+	}
+)
+
+func (this *standardDialect) Placeholder(index int) string {
+
+	if(!this.numbered) {
+		return this.prefix
+	}
+	return this.prefix + strconv.Itoa(index + 1)
+}
+
+func (this *standardDialect) ReusesPlaceholders() bool {
+	return this.numbered
+}
+
+func (this *standardDialect) Syntax() SyntaxRules {
+	return this.syntax
+}
+
+func (this *standardDialect) MaxParameters() int {
+	return this.maxParameters
+}
+
+func (this *standardDialect) QuoteIdentifier(name string) string {
+
+	opening := this.quotes[:1]
+	closing := this.quotes[1:]
+
+	// a closing quote inside the name is escaped by doubling it.
+	return opening + strings.Replace(name, closing, closing + closing, -1) + closing
+}
+
+func (this *standardDialect) String() string {
+	return this.name
+}
@@ -0,0 +1,123 @@
+package namedParameterQuery
+
+import (
+	"testing"
+)
+
+/*
+	Represents a single test of dialect-specific query parsing.
+	Given an [Input] query parsed with [Dialect], the parsed query must match [Expected],
+	and the parameters must match [ExpectedParameters] after [Parameters] are set.
+*/
+type DialectParsingTest struct {
+	Name string
+	Dialect Dialect
+	Input string
+	Expected string
+	Parameters []TestQueryParameter
+	ExpectedParameters []interface{}
+}
+
+func TestDialectParsing(test *testing.T) {
+
+	var query *NamedParameterQuery
+
+	dialectTests := []DialectParsingTest {
+		DialectParsingTest {
+			Name: "MySQL",
+			Dialect: MySQLDialect,
+			Input: "SELECT * FROM table WHERE col1 = :foo AND col2 = :bar AND col3 = :foo",
+			Expected: "SELECT * FROM table WHERE col1 = ? AND col2 = ? AND col3 = ?",
+			Parameters: []TestQueryParameter {
+				TestQueryParameter { Name: "foo", Value: 1 },
+				TestQueryParameter { Name: "bar", Value: 2 },
+			},
+			ExpectedParameters: []interface{} { 1, 2, 1 },
+		},
+		DialectParsingTest {
+			Name: "SQLite",
+			Dialect: SQLiteDialect,
+			Input: "SELECT * FROM table WHERE col1 = :foo",
+			Expected: "SELECT * FROM table WHERE col1 = ?",
+			Parameters: []TestQueryParameter {
+				TestQueryParameter { Name: "foo", Value: 1 },
+			},
+			ExpectedParameters: []interface{} { 1 },
+		},
+		DialectParsingTest {
+			Name: "Postgres",
+			Dialect: PostgresDialect,
+			Input: "SELECT * FROM table WHERE col1 = :foo AND col2 = :bar AND col3 = :foo",
+			Expected: "SELECT * FROM table WHERE col1 = $1 AND col2 = $2 AND col3 = $1",
+			Parameters: []TestQueryParameter {
+				TestQueryParameter { Name: "foo", Value: 1 },
+				TestQueryParameter { Name: "bar", Value: 2 },
+			},
+			ExpectedParameters: []interface{} { 1, 2 },
+		},
+		DialectParsingTest {
+			Name: "Oracle",
+			Dialect: OracleDialect,
+			Input: "SELECT * FROM table WHERE col1 = :foo AND col2 = :bar AND col3 = :foo",
+			Expected: "SELECT * FROM table WHERE col1 = :1 AND col2 = :2 AND col3 = :1",
+			Parameters: []TestQueryParameter {
+				TestQueryParameter { Name: "foo", Value: 1 },
+				TestQueryParameter { Name: "bar", Value: 2 },
+			},
+			ExpectedParameters: []interface{} { 1, 2 },
+		},
+		DialectParsingTest {
+			Name: "SQLServer",
+			Dialect: SQLServerDialect,
+			Input: "SELECT * FROM table WHERE col1 = :foo AND col2 = :bar AND col3 = :foo",
+			Expected: "SELECT * FROM table WHERE col1 = @p1 AND col2 = @p2 AND col3 = @p1",
+			Parameters: []TestQueryParameter {
+				TestQueryParameter { Name: "foo", Value: 1 },
+				TestQueryParameter { Name: "bar", Value: 2 },
+			},
+			ExpectedParameters: []interface{} { 1, 2 },
+		},
+	}
+
+	for _, dialectTest := range dialectTests {
+
+		query = NewNamedParameterQueryWithDialect(dialectTest.Input, dialectTest.Dialect)
+
+		if(query.GetParsedQuery() != dialectTest.Expected) {
+			test.Log("Test '", dialectTest.Name, "': Expected query text did not match actual parsed output")
+			test.Log("Actual: ", query.GetParsedQuery())
+			test.Fail()
+		}
+
+		for _, queryVariable := range dialectTest.Parameters {
+			query.SetValue(queryVariable.Name, queryVariable.Value)
+		}
+
+		verifyStructParameters(dialectTest.Name, test, query, dialectTest.ExpectedParameters)
+	}
+}
+
+func TestQuoteIdentifier(test *testing.T) {
+
+	quotingTests := map[Dialect]string {
+		MySQLDialect: "`we``ird`",
+		SQLiteDialect: "\"we`ird\"",
+		PostgresDialect: "\"we`ird\"",
+		OracleDialect: "\"we`ird\"",
+		SQLServerDialect: "[we`ird]",
+	}
+
+	for dialect, expected := range quotingTests {
+
+		actual := dialect.QuoteIdentifier("we`ird")
+		if(actual != expected) {
+			test.Log("Dialect ", dialect, ": Expected quoted identifier ", expected, ", got: ", actual)
+			test.Fail()
+		}
+	}
+
+	if(SQLServerDialect.QuoteIdentifier("a]b") != "[a]]b]" || PostgresDialect.QuoteIdentifier("a\"b") != "\"a\"\"b\"") {
+		test.Log("Expected closing quotes inside identifiers to be doubled")
+		test.Fail()
+	}
+}
@@ -0,0 +1,438 @@
+package namedParameterQuery
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+)
+
+/*
+	WrapDriver wraps the given [parent] driver so that queries may use named parameters,
+	with values given as sql.Named arguments, regardless of whether the parent driver supports them:
+
+		sql.Register("postgres-named", WrapDriver(&pq.Driver{}, QueryOptions { Dialect: PostgresDialect }))
+
+		db, _ := sql.Open("postgres-named", dataSourceName)
+		db.Query("SELECT * FROM users WHERE id = :id", sql.Named("id", 5))
+
+	Queries which contain named parameters are rewritten into positional queries for the parent driver,
+	and each sql.Named argument is moved into position. Arguments without names are passed through untouched,
+	as are queries without named parameters. A query which can't be parsed is passed through untouched too,
+	unless it's given sql.Named arguments, in which case the *ParseError is returned.
+
+	Slice values are expanded as they are by Binding, but only when the parent driver can run queries
+	without preparing them first (i.e. implements driver.QueryerContext and driver.ExecerContext), since
+	a prepared statement's placeholders are fixed before its arguments are known.
+*/
+func WrapDriver(parent driver.Driver, options QueryOptions) (driver.Driver) {
+	return &namedDriver { parent: parent, options: options }
+}
+
+/*
+	WrapConnector wraps the given [parent] connector in the same way as WrapDriver, for use with sql.OpenDB.
+*/
+func WrapConnector(parent driver.Connector, options QueryOptions) (driver.Connector) {
+	return &namedConnector { parent: parent, options: options }
+}
+
+type namedDriver struct {
+	parent driver.Driver
+	options QueryOptions
+}
+
+type namedConnector struct {
+	parent driver.Connector
+	options QueryOptions
+}
+
+/*
+	dsnConnector connects using a data source name, for parent drivers which don't implement driver.DriverContext.
+*/
+type dsnConnector struct {
+	driver *namedDriver
+	name string
+}
+
+type namedConn struct {
+	parent driver.Conn
+	options QueryOptions
+}
+
+type namedStmt struct {
+	parent driver.Stmt
+	template *QueryTemplate
+	conn *namedConn
+
+	// If the query couldn't be parsed, the reason, which is returned if the statement is given named arguments.
+	parseError error
+}
+
+func (this *namedDriver) Open(name string) (driver.Conn, error) {
+
+	conn, err := this.parent.Open(name)
+	if(err != nil) {
+		return nil, err
+	}
+	return &namedConn { parent: conn, options: this.options }, nil
+}
+
+func (this *namedDriver) OpenConnector(name string) (driver.Connector, error) {
+
+	parentContext, ok := this.parent.(driver.DriverContext)
+	if(!ok) {
+		return &dsnConnector { driver: this, name: name }, nil
+	}
+
+	connector, err := parentContext.OpenConnector(name)
+	if(err != nil) {
+		return nil, err
+	}
+	return WrapConnector(connector, this.options), nil
+}
+
+func (this *dsnConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	return this.driver.Open(this.name)
+}
+
+func (this *dsnConnector) Driver() (driver.Driver) {
+	return this.driver
+}
+
+func (this *namedConnector) Connect(ctx context.Context) (driver.Conn, error) {
+
+	conn, err := this.parent.Connect(ctx)
+	if(err != nil) {
+		return nil, err
+	}
+	return &namedConn { parent: conn, options: this.options }, nil
+}
+
+func (this *namedConnector) Driver() (driver.Driver) {
+	return WrapDriver(this.parent.Driver(), this.options)
+}
+
+func (this *namedConn) Prepare(query string) (driver.Stmt, error) {
+	return this.PrepareContext(context.Background(), query)
+}
+
+func (this *namedConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+
+	var stmt driver.Stmt
+	var err error
+
+	// a query which can't be parsed is prepared as it is, since the parent may understand it better.
+	template, parseError := DefaultTemplateCache.Parse(query, this.options)
+	if(parseError == nil) {
+
+		err = checkPreparable(template)
+		if(err != nil) {
+			return nil, err
+		}
+		query = template.GetParsedQuery()
+	}
+
+	preparer, ok := this.parent.(driver.ConnPrepareContext)
+	if(ok) {
+		stmt, err = preparer.PrepareContext(ctx, query)
+	} else {
+		stmt, err = this.parent.Prepare(query)
+	}
+
+	if(err != nil) {
+		return nil, err
+	}
+
+	// a query without named parameters is left entirely to the parent.
+	if(parseError == nil && len(template.positions) == 0) {
+		return stmt, nil
+	}
+	return &namedStmt { parent: stmt, template: template, conn: this, parseError: parseError }, nil
+}
+
+func (this *namedConn) Close() (error) {
+	return this.parent.Close()
+}
+
+func (this *namedConn) Begin() (driver.Tx, error) {
+	return this.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+func (this *namedConn) BeginTx(ctx context.Context, options driver.TxOptions) (driver.Tx, error) {
+
+	beginner, ok := this.parent.(driver.ConnBeginTx)
+	if(ok) {
+		return beginner.BeginTx(ctx, options)
+	}
+
+	if(options.Isolation != 0 || options.ReadOnly) {
+		return nil, errors.New("Unable to begin transaction: driver does not support transaction options")
+	}
+	return this.parent.Begin()
+}
+
+func (this *namedConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+
+	queryer, ok := this.parent.(driver.QueryerContext)
+	if(!ok) {
+		return nil, driver.ErrSkip
+	}
+
+	query, args, err := this.rewrite(query, args)
+	if(err != nil) {
+		return nil, err
+	}
+	return queryer.QueryContext(ctx, query, args)
+}
+
+func (this *namedConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+
+	execer, ok := this.parent.(driver.ExecerContext)
+	if(!ok) {
+		return nil, driver.ErrSkip
+	}
+
+	query, args, err := this.rewrite(query, args)
+	if(err != nil) {
+		return nil, err
+	}
+	return execer.ExecContext(ctx, query, args)
+}
+
+/*
+	CheckNamedValue lets slice values through untouched, since they're expanded later, and
+	checks everything else as the parent driver would.
+*/
+func (this *namedConn) CheckNamedValue(value *driver.NamedValue) (error) {
+
+	if(isExpandable(value.Value)) {
+		return nil
+	}
+
+	checker, ok := this.parent.(driver.NamedValueChecker)
+	if(ok) {
+		return checker.CheckNamedValue(value)
+	}
+
+	converted, err := driver.DefaultParameterConverter.ConvertValue(value.Value)
+	if(err != nil) {
+		return err
+	}
+
+	value.Value = converted
+	return nil
+}
+
+func (this *namedConn) Ping(ctx context.Context) (error) {
+
+	pinger, ok := this.parent.(driver.Pinger)
+	if(!ok) {
+		return nil
+	}
+	return pinger.Ping(ctx)
+}
+
+func (this *namedConn) ResetSession(ctx context.Context) (error) {
+
+	resetter, ok := this.parent.(driver.SessionResetter)
+	if(!ok) {
+		return nil
+	}
+	return resetter.ResetSession(ctx)
+}
+
+func (this *namedConn) IsValid() (bool) {
+
+	validator, ok := this.parent.(driver.Validator)
+	if(!ok) {
+		return true
+	}
+	return validator.IsValid()
+}
+
+/*
+	rewrite turns the given [query] and named [args] into a positional query and arguments for the parent driver.
+	This follows the same rules as a prepared statement; arguments without names are returned untouched,
+	as is a query without named parameters, or a query which can't be parsed and isn't given named arguments.
+*/
+func (this *namedConn) rewrite(query string, args []driver.NamedValue) (string, []driver.NamedValue, error) {
+
+	template, err := DefaultTemplateCache.Parse(query, this.options)
+	if(err != nil) {
+
+		if(hasNamedValues(args)) {
+			return "", nil, err
+		}
+		return query, args, nil
+	}
+
+	if(len(template.positions) == 0) {
+		return query, args, nil
+	}
+
+	err = checkPreparable(template)
+	if(err != nil) {
+		return "", nil, err
+	}
+
+	if(!hasNamedValues(args)) {
+		return template.GetParsedQuery(), args, nil
+	}
+
+	binding, err := bindNamedValues(template, args)
+	if(err != nil) {
+		return "", nil, err
+	}
+
+	args, err = this.positionalValues(binding)
+	if(err != nil) {
+		return "", nil, err
+	}
+	return binding.GetParsedQuery(), args, nil
+}
+
+/*
+	positionalValues returns the parameters of the given [binding] as positional driver values.
+	Expanded slice elements haven't been checked yet, so they're checked here.
+*/
+func (this *namedConn) positionalValues(binding *Binding) ([]driver.NamedValue, error) {
+
+	var ret []driver.NamedValue
+	var err error
+
+	parameters := binding.GetParsedParameters()
+	ret = make([]driver.NamedValue, len(parameters))
+
+	for index, parameter := range parameters {
+
+		ret[index] = driver.NamedValue { Ordinal: index + 1, Value: parameter }
+
+		if(len(binding.expanded) > 0) {
+
+			err = this.CheckNamedValue(&ret[index])
+			if(err != nil) {
+				return nil, err
+			}
+		}
+	}
+	return ret, nil
+}
+
+/*
+	hasNamedValues returns true if any of the given [args] has a name.
+*/
+func hasNamedValues(args []driver.NamedValue) (bool) {
+
+	for _, arg := range args {
+		if(len(arg.Name) > 0) {
+			return true
+		}
+	}
+	return false
+}
+
+/*
+	bindNamedValues binds each of the given [args] to a new binding of the given [template] by name.
+*/
+func bindNamedValues(template *QueryTemplate, args []driver.NamedValue) (*Binding, error) {
+
+	binding := template.Bind()
+
+	for _, arg := range args {
+
+		if(len(arg.Name) == 0) {
+			return nil, fmt.Errorf("Unable to bind query: argument %d has no name, but the query uses named parameters", arg.Ordinal)
+		}
+		binding.SetValue(arg.Name, arg.Value)
+	}
+
+	err := binding.Validate()
+	if(err != nil) {
+		return nil, err
+	}
+	return binding, nil
+}
+
+func (this *namedStmt) Close() (error) {
+	return this.parent.Close()
+}
+
+/*
+	NumInput returns -1, since the number of named arguments needn't match the number of positional parameters.
+*/
+func (this *namedStmt) NumInput() (int) {
+	return -1
+}
+
+func (this *namedStmt) Exec(args []driver.Value) (driver.Result, error) {
+	return this.parent.Exec(args)
+}
+
+func (this *namedStmt) Query(args []driver.Value) (driver.Rows, error) {
+	return this.parent.Query(args)
+}
+
+func (this *namedStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+
+	args, err := this.bind(args)
+	if(err != nil) {
+		return nil, err
+	}
+
+	execer, ok := this.parent.(driver.StmtExecContext)
+	if(ok) {
+		return execer.ExecContext(ctx, args)
+	}
+	return this.parent.Exec(namedValuesToValues(args))
+}
+
+func (this *namedStmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+
+	args, err := this.bind(args)
+	if(err != nil) {
+		return nil, err
+	}
+
+	queryer, ok := this.parent.(driver.StmtQueryContext)
+	if(ok) {
+		return queryer.QueryContext(ctx, args)
+	}
+	return this.parent.Query(namedValuesToValues(args))
+}
+
+/*
+	bind moves the given named [args] into position for the prepared statement.
+	Since the prepared query is fixed, slice values can't be expanded, and optional fragments can't be left out.
+	Without any named arguments, the [args] are left as they are.
+*/
+func (this *namedStmt) bind(args []driver.NamedValue) ([]driver.NamedValue, error) {
+
+	if(!hasNamedValues(args)) {
+		return args, nil
+	}
+	if(this.parseError != nil) {
+		return nil, this.parseError
+	}
+
+	binding, err := bindNamedValues(this.template, args)
+	if(err != nil) {
+		return nil, err
+	}
+
+	if(len(binding.expanded) > 0) {
+		return nil, errors.New("Unable to execute prepared statement: slice values cannot be expanded in a prepared statement")
+	}
+	if(binding.dropped) {
+		return nil, errors.New("Unable to execute prepared statement: optional fragments cannot be left out of a prepared statement")
+	}
+	return this.conn.positionalValues(binding)
+}
+
+func namedValuesToValues(args []driver.NamedValue) ([]driver.Value) {
+
+	ret := make([]driver.Value, len(args))
+	for index, arg := range args {
+		ret[index] = arg.Value
+	}
+	return ret
+}
@@ -0,0 +1,114 @@
+package namedParameterQuery
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"testing"
+)
+
+func TestWrapConnector(test *testing.T) {
+
+	var name string
+
+	for _, direct := range []bool { false, true } {
+
+		fake := &fakeDriver { columns: []string { "name" }, rows: [][]driver.Value { { "carol" } }, direct: direct }
+		db := sql.OpenDB(WrapConnector(fake, QueryOptions { Dialect: PostgresDialect }))
+
+		_, err := db.Exec("UPDATE table SET col1 = :foo WHERE col2 = :bar OR col3 = :foo", sql.Named("bar", 2), sql.Named("foo", "x"))
+		verifyFakeStatement("NamedExec", test, err, fake, "UPDATE table SET col1 = $1 WHERE col2 = $2 OR col3 = $1", "x", int64(2))
+
+		err = db.QueryRow("SELECT name FROM table WHERE col1 = :foo::text", sql.Named("foo", "y")).Scan(&name)
+		verifyFakeStatement("NamedQueryRow", test, err, fake, "SELECT name FROM table WHERE col1 = $1::text", "y")
+
+		if(name != "carol") {
+			test.Log("Expected the row to be scanned, got: ", name)
+			test.Fail()
+		}
+
+		// positional queries are left alone.
+		_, err = db.Exec("DELETE FROM table WHERE col1 = $1", "z")
+		verifyFakeStatement("PositionalExec", test, err, fake, "DELETE FROM table WHERE col1 = $1", "z")
+
+		// arguments without names are left as they are, whether or not the parent prepares the query.
+		_, err = db.Exec("DELETE FROM table WHERE col1 = :foo", "z")
+		verifyFakeStatement("PositionalArguments", test, err, fake, "DELETE FROM table WHERE col1 = $1", "z")
+
+		// "#" isn't a comment in Postgres, so this can't be parsed, and the parse error is better than the driver's.
+		_, err = db.Exec("DELETE FROM table WHERE col1 = :foo # it's", sql.Named("foo", "z"))
+		_, isParseError := err.(*ParseError)
+		if(!isParseError) {
+			test.Log("Expected named arguments to an unparseable query to return the parse error, got: ", err)
+			test.Fail()
+		}
+
+		_, err = db.Exec("DELETE FROM table WHERE col1 = :foo", sql.Named("bar", "z"))
+		if(err == nil) {
+			test.Log("Expected an unbound parameter to be rejected")
+			test.Fail()
+		}
+
+		db.Close()
+	}
+}
+
+/*
+	Ensures that queries which don't need rewriting reach the parent driver untouched, even if they can't be parsed.
+*/
+func TestWrapConnectorPassThrough(test *testing.T) {
+
+	for _, direct := range []bool { false, true } {
+
+		fake := &fakeDriver { direct: direct }
+
+		mysql := sql.OpenDB(WrapConnector(fake, QueryOptions { Dialect: MySQLDialect }))
+		_, err := mysql.Exec("SELECT 1 # it's")
+		verifyFakeStatement("HashComment", test, err, fake, "SELECT 1 # it's")
+		mysql.Close()
+
+		postgres := sql.OpenDB(WrapConnector(fake, QueryOptions { Dialect: PostgresDialect }))
+
+		// "#" isn't a comment in Postgres, so this can't be parsed.
+		_, err = postgres.Exec("SELECT 1 # it's")
+		verifyFakeStatement("Unparseable", test, err, fake, "SELECT 1 # it's")
+
+		_, err = postgres.Exec("SELECT arr[1:2] FROM table WHERE id = $1", 5)
+		verifyFakeStatement("ArraySlice", test, err, fake, "SELECT arr[1:2] FROM table WHERE id = $1", int64(5))
+		postgres.Close()
+	}
+}
+
+func TestWrapConnectorSliceExpansion(test *testing.T) {
+
+	fake := &fakeDriver { direct: true }
+	db := sql.OpenDB(WrapConnector(fake, QueryOptions{}))
+	defer db.Close()
+
+	_, err := db.Exec("DELETE FROM table WHERE id IN (:ids)", sql.Named("ids", []int { 1, 2 }))
+	verifyFakeStatement("SliceExpansion", test, err, fake, "DELETE FROM table WHERE id IN (?, ?)", int64(1), int64(2))
+}
+
+func TestWrapDriver(test *testing.T) {
+
+	fake := &fakeDriver{}
+	wrapped := WrapDriver(fake, QueryOptions { Dialect: OracleDialect })
+
+	connector, err := wrapped.(driver.DriverContext).OpenConnector("")
+	if(err != nil) {
+		test.Log("Unable to open connector: ", err)
+		test.FailNow()
+	}
+
+	db := sql.OpenDB(connector)
+	defer db.Close()
+
+	stmt, err := db.Prepare("DELETE FROM table WHERE col1 = :foo AND col2 = :bar")
+	if(err != nil) {
+		test.Log("Unable to prepare statement: ", err)
+		test.FailNow()
+	}
+	defer stmt.Close()
+
+	_, err = stmt.Exec(sql.Named("foo", "a"), sql.Named("bar", "b"))
+	verifyFakeStatement("PreparedNamedExec", test, err, fake, "DELETE FROM table WHERE col1 = :1 AND col2 = :2", "a", "b")
+}
@@ -0,0 +1,151 @@
+package namedParameterQuery
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+/*
+	ParseErrorKind identifies what was wrong with a query that could not be parsed.
+*/
+type ParseErrorKind int
+
+const (
+
+	// A quoted string (including dollar-quoted strings) was never closed.
+	UnterminatedString ParseErrorKind = iota + 1
+
+	// A quoted identifier was never closed.
+	UnterminatedIdentifier
+
+	// A block comment was never closed.
+	UnterminatedComment
+
+	// A parameter prefix was not followed by any parameter name.
+	EmptyParameterName
+
+	// A parameter name ran into a character that looks like part of the name, but is not allowed in one.
+	InvalidParameterName
+
+	// A braced parameter or slot, such as "${name}" or "{{name}}", was never closed.
+	UnterminatedParameter
+
+	// An optional fragment, "/*[ ... ]*/", was never closed.
+	UnterminatedFragment
+)
+
+var parseErrorDescriptions = map[ParseErrorKind]string {
+	UnterminatedString: "unterminated string",
+	UnterminatedIdentifier: "unterminated quoted identifier",
+	UnterminatedComment: "unterminated comment",
+	EmptyParameterName: "empty parameter name",
+	InvalidParameterName: "invalid parameter name",
+	UnterminatedParameter: "unterminated parameter",
+	UnterminatedFragment: "unterminated optional fragment",
+}
+
+func (this ParseErrorKind) String() string {
+
+	description, found := parseErrorDescriptions[this]
+	if(!found) {
+		return fmt.Sprintf("unknown parse error (%d)", int(this))
+	}
+	return description
+}
+
+/*
+	ParseError is returned by ParseNamedParameterQuery when a query is malformed.
+	It records what was wrong, and where in the query the problem begins.
+*/
+type ParseError struct {
+
+	Kind ParseErrorKind
+
+	// The byte offset into the query at which the problem begins.
+	Offset int
+
+	// The one-based line and column (in characters) at which the problem begins.
+	Line int
+	Column int
+}
+
+/*
+	newParseError creates a ParseError of the given [kind] at the given byte [offset] of [query],
+	working out the line and column of that offset.
+*/
+func newParseError(kind ParseErrorKind, query string, offset int) (*ParseError) {
+
+	var preceding string
+	var lineStart int
+
+	preceding = query[:offset]
+	lineStart = strings.LastIndexByte(preceding, '\n') + 1
+
+	return &ParseError {
+		Kind: kind,
+		Offset: offset,
+		Line: strings.Count(preceding, "\n") + 1,
+		Column: utf8.RuneCountInString(preceding[lineStart:]) + 1,
+	}
+}
+
+func (this *ParseError) Error() string {
+	return fmt.Sprintf("Unable to parse query: %s at line %d, column %d", this.Kind, this.Line, this.Column)
+}
+
+/*
+	BindingError is returned by Validate when a query's parameters have not been bound correctly.
+	It lists every offending parameter name, in alphabetical order.
+*/
+type BindingError struct {
+
+	// Parameters which appear in the query, but have had no value set.
+	Missing []string
+
+	// Names which were given values, but do not appear in the query. Only recorded by strict queries.
+	Unknown []string
+
+	// Parameters which were given empty slices, and so cannot be expanded into any placeholders.
+	Empty []string
+
+	// Slots which have not had a query composed into them. See QueryTemplate.Compose.
+	Unfilled []string
+}
+
+func newBindingError(missing []string, unknown []string, empty []string, unfilled []string) (*BindingError) {
+
+	var ret *BindingError
+
+	ret = new(BindingError)
+	ret.Missing = append([]string(nil), missing...)
+	ret.Unknown = append([]string(nil), unknown...)
+	ret.Empty = append([]string(nil), empty...)
+	ret.Unfilled = append([]string(nil), unfilled...)
+
+	sort.Strings(ret.Missing)
+	sort.Strings(ret.Unknown)
+	sort.Strings(ret.Empty)
+	sort.Strings(ret.Unfilled)
+	return ret
+}
+
+func (this *BindingError) Error() string {
+
+	var problems []string
+
+	if(len(this.Missing) > 0) {
+		problems = append(problems, "missing parameters: " + strings.Join(this.Missing, ", "))
+	}
+	if(len(this.Unknown) > 0) {
+		problems = append(problems, "unknown parameters: " + strings.Join(this.Unknown, ", "))
+	}
+	if(len(this.Empty) > 0) {
+		problems = append(problems, "empty slices: " + strings.Join(this.Empty, ", "))
+	}
+	if(len(this.Unfilled) > 0) {
+		problems = append(problems, "unfilled slots: " + strings.Join(this.Unfilled, ", "))
+	}
+	return "Unable to bind query: " + strings.Join(problems, "; ")
+}
@@ -0,0 +1,157 @@
+package namedParameterQuery
+
+import (
+	"testing"
+)
+
+/*
+	Represents a single test of a malformed query.
+	Parsing [Input] with [Options] must fail with a ParseError of kind [Kind], at [Line] and [Column].
+*/
+type ParseErrorTest struct {
+	Name string
+	Input string
+	Options QueryOptions
+	Kind ParseErrorKind
+	Line int
+	Column int
+}
+
+func TestParseErrors(test *testing.T) {
+
+	var parseError *ParseError
+	var ok bool
+
+	parseErrorTests := []ParseErrorTest {
+		ParseErrorTest {
+			Name: "UnterminatedString",
+			Input: "SELECT * FROM table WHERE col1 = 'oops",
+			Kind: UnterminatedString,
+			Line: 1,
+			Column: 34,
+		},
+		ParseErrorTest {
+			Name: "UnterminatedIdentifier",
+			Input: "SELECT \"oops FROM table",
+			Kind: UnterminatedIdentifier,
+			Line: 1,
+			Column: 8,
+		},
+		ParseErrorTest {
+			Name: "UnterminatedComment",
+			Input: "SELECT *\nFROM table /* oops",
+			Kind: UnterminatedComment,
+			Line: 2,
+			Column: 12,
+		},
+		ParseErrorTest {
+			Name: "EmptyParameterName",
+			Input: "SELECT * FROM table WHERE col1 = : AND col2 = :foo",
+			Kind: EmptyParameterName,
+			Line: 1,
+			Column: 34,
+		},
+		ParseErrorTest {
+			Name: "EmptyParameterNameAtEnd",
+			Input: "SELECT * FROM table WHERE col1 = :",
+			Kind: EmptyParameterName,
+			Line: 1,
+			Column: 34,
+		},
+		ParseErrorTest {
+			Name: "InvalidParameterName",
+			Input: "SELECT * FROM table WHERE col1 = :café",
+			Options: QueryOptions { Identifiers: ASCIIIdentifiers },
+			Kind: InvalidParameterName,
+			Line: 1,
+			Column: 38,
+		},
+		ParseErrorTest {
+			Name: "UnterminatedFragment",
+			Input: "SELECT * FROM table WHERE 1 = 1 /*[ AND col1 = :foo",
+			Options: QueryOptions { OptionalFragments: true },
+			Kind: UnterminatedFragment,
+			Line: 1,
+			Column: 33,
+		},
+		ParseErrorTest {
+			Name: "UnterminatedSlot",
+			Input: "SELECT * FROM table WHERE {{filter",
+			Options: QueryOptions { Slots: true },
+			Kind: UnterminatedParameter,
+			Line: 1,
+			Column: 27,
+		},
+		ParseErrorTest {
+			Name: "UnterminatedBracedParameter",
+			Input: "SELECT * FROM table WHERE col1 = ${foo",
+			Options: QueryOptions { Prefixes: DollarBracePrefix },
+			Kind: UnterminatedParameter,
+			Line: 1,
+			Column: 34,
+		},
+		ParseErrorTest {
+			Name: "EmptyBracedParameter",
+			Input: "SELECT * FROM table WHERE col1 = #{ }",
+			Options: QueryOptions { Prefixes: HashBracePrefix },
+			Kind: EmptyParameterName,
+			Line: 1,
+			Column: 34,
+		},
+	}
+
+	for _, parseErrorTest := range parseErrorTests {
+
+		query, err := ParseNamedParameterQueryWithOptions(parseErrorTest.Input, parseErrorTest.Options)
+
+		if(query != nil) {
+			test.Log("Test '", parseErrorTest.Name, "': Expected no query to be returned for a malformed query")
+			test.Fail()
+		}
+
+		parseError, ok = err.(*ParseError)
+		if(!ok) {
+			test.Log("Test '", parseErrorTest.Name, "': Expected a ParseError, got: ", err)
+			test.Fail()
+			continue
+		}
+
+		if(parseError.Kind != parseErrorTest.Kind || parseError.Line != parseErrorTest.Line || parseError.Column != parseErrorTest.Column) {
+			test.Log("Test '", parseErrorTest.Name, "': Expected '", parseErrorTest.Kind, "' at ", parseErrorTest.Line, ":", parseErrorTest.Column, ", got: ", err)
+			test.Fail()
+		}
+	}
+}
+
+func TestParseSuccess(test *testing.T) {
+
+	query, err := ParseNamedParameterQuery("SELECT * FROM table WHERE col1 = :foo AND col2 = ':bar'")
+
+	if(err != nil) {
+		test.Log("Expected well-formed query to parse, got: ", err)
+		test.FailNow()
+	}
+
+	if(query.GetParsedQuery() != "SELECT * FROM table WHERE col1 = ? AND col2 = ':bar'") {
+		test.Log("Unexpected parsed query: ", query.GetParsedQuery())
+		test.Fail()
+	}
+}
+
+/*
+	Ensures that the lenient constructor still produces a query from malformed input.
+*/
+func TestLenientParsing(test *testing.T) {
+
+	query := NewNamedParameterQuery("SELECT * FROM table WHERE col1 = : AND col2 = :foo")
+
+	if(query.GetParsedQuery() != "SELECT * FROM table WHERE col1 = : AND col2 = ?") {
+		test.Log("Unexpected parsed query: ", query.GetParsedQuery())
+		test.Fail()
+	}
+
+	if(len(query.GetParsedParameters()) != 1) {
+		test.Log("Expected a lone colon not to be counted as a parameter")
+		test.Fail()
+	}
+}
@@ -0,0 +1,232 @@
+package namedParameterQuery
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"reflect"
+)
+
+/*
+	EmptySlicePolicy determines what happens when a parameter is given an empty slice,
+	e.g. "WHERE id IN (:ids)" with no ids.
+*/
+type EmptySlicePolicy int
+
+const (
+
+	// An empty slice is reported as a BindingError by Validate and Args. This is the default.
+	EmptySlicesFail EmptySlicePolicy = iota
+
+	// An empty slice is written as "NULL", so that "IN (:ids)" becomes "IN (NULL)", which matches nothing.
+	// Be careful with "NOT IN", since "NOT IN (NULL)" matches nothing, too.
+	EmptySlicesAsNull
+)
+
+/*
+	isExpandable returns true if the given [value] is a slice or array which should be expanded
+	into one positional parameter per element. Byte slices, and anything which implements driver.Valuer,
+	are passed to the driver as a single value instead.
+*/
+func isExpandable(value interface{}) bool {
+
+	var valueType reflect.Type
+
+	if(value == nil) {
+		return false
+	}
+
+	valueType = reflect.TypeOf(value)
+
+	if(valueType.Kind() != reflect.Slice && valueType.Kind() != reflect.Array) {
+		return false
+	}
+
+	if(valueType.Elem().Kind() == reflect.Uint8) {
+		return false
+	}
+
+	_, isValuer := value.(driver.Valuer)
+	return !isValuer
+}
+
+/*
+	render builds the positional query and parameters for this binding, expanding any slice values
+	into one placeholder per element, and leaving out any optional fragments whose parameters are unset.
+	The result is kept until another value is set.
+*/
+func (this *Binding) render() {
+
+	var queryBuilder bytes.Buffer
+	var parameters []interface{}
+	var empty []string
+	var dialect Dialect
+	var written map[string]string
+	var placeholders string
+	var found bool
+	var included []bool
+	var fragment int
+	var skipping int
+
+	if(this.rendered) {
+		return
+	}
+
+	this.rendered = true
+	included, this.dropped = this.includedFragments()
+
+	// without any slices to expand, fragments to leave out, or identifiers to write, the template already has everything.
+	if(len(this.expanded) == 0 && !this.dropped && len(this.template.identifierNames) == 0) {
+		this.renderedQuery = this.template.revisedQuery
+		this.renderedParameters = this.parameters
+		this.empty = nil
+		return
+	}
+
+	dialect = this.template.options.Dialect
+	parameters = make([]interface{}, 0, len(this.parameters))
+	written = make(map[string]string, len(this.template.positions))
+
+	for _, segment := range this.template.segments {
+
+		switch segment.kind {
+		case optionalStartSegment:
+
+			// everything inside a left out fragment is left out too, including nested fragments.
+			if(skipping > 0 || !included[fragment]) {
+				skipping++
+			}
+			fragment++
+			continue
+
+		case optionalEndSegment:
+
+			if(skipping > 0) {
+				skipping--
+			}
+			continue
+		}
+
+		if(skipping > 0) {
+			continue
+		}
+
+		// an identifier which hasn't been set is left as it is, which Validate will report.
+		if(segment.kind == identifierSegment) {
+
+			placeholders, found = this.identifiers[segment.name]
+			if(!found) {
+				placeholders = segment.text
+			}
+			queryBuilder.WriteString(placeholders)
+			continue
+		}
+
+		if(segment.kind != parameterSegment) {
+			queryBuilder.WriteString(segment.text)
+			continue
+		}
+
+		// numbered dialects can refer back to everything written for an earlier occurrence.
+		placeholders, found = written[segment.name]
+		if(found) {
+			queryBuilder.WriteString(placeholders)
+			continue
+		}
+
+		placeholders, parameters = this.expand(segment.name, dialect, parameters)
+		if(len(placeholders) == 0) {
+			empty = appendUnique(empty, segment.name)
+			placeholders = "NULL"
+		}
+
+		if(dialect.ReusesPlaceholders()) {
+			written[segment.name] = placeholders
+		}
+		queryBuilder.WriteString(placeholders)
+	}
+
+	this.renderedQuery = queryBuilder.String()
+	this.renderedParameters = parameters
+	this.empty = nil
+
+	if(this.template.options.EmptySlices == EmptySlicesFail) {
+		this.empty = empty
+	}
+}
+
+/*
+	includedFragments returns, for each optional fragment in this binding's query (in order of their starts),
+	whether every parameter directly inside it has been set, and whether any fragment is to be left out.
+*/
+func (this *Binding) includedFragments() ([]bool, bool) {
+
+	var ret []bool
+	var open []int
+	var started int
+	var dropped bool
+
+	if(this.template.fragments == 0) {
+		return nil, false
+	}
+
+	ret = make([]bool, this.template.fragments)
+	open = make([]int, 0, 4)
+
+	for _, segment := range this.template.segments {
+
+		switch segment.kind {
+		case optionalStartSegment:
+			ret[started] = true
+			open = append(open, started)
+			started++
+
+		case optionalEndSegment:
+			open = open[:len(open) - 1]
+
+		case parameterSegment:
+			if(len(open) > 0 && !this.bound[this.template.positions[segment.name][0]]) {
+				ret[open[len(open) - 1]] = false
+				dropped = true
+			}
+
+		case identifierSegment:
+			if(len(open) > 0 && len(this.identifiers[segment.name]) == 0) {
+				ret[open[len(open) - 1]] = false
+				dropped = true
+			}
+		}
+	}
+	return ret, dropped
+}
+
+/*
+	expand returns the placeholders for the parameter of the given [name], appending its values to [parameters].
+	A scalar value gets a single placeholder; a slice gets one per element, separated by commas,
+	and an empty slice gets none.
+*/
+func (this *Binding) expand(name string, dialect Dialect, parameters []interface{}) (string, []interface{}) {
+
+	var placeholders bytes.Buffer
+	var value interface{}
+	var values reflect.Value
+
+	value = this.parameters[this.template.positions[name][0]]
+
+	if(!this.expanded[name]) {
+		return dialect.Placeholder(len(parameters)), append(parameters, value)
+	}
+
+	values = reflect.ValueOf(value)
+
+	for i := 0; i < values.Len(); i++ {
+
+		if(i > 0) {
+			placeholders.WriteString(", ")
+		}
+
+		placeholders.WriteString(dialect.Placeholder(len(parameters)))
+		parameters = append(parameters, values.Index(i).Interface())
+	}
+
+	return placeholders.String(), parameters
+}
@@ -0,0 +1,261 @@
+package namedParameterQuery
+
+import (
+	"database/sql/driver"
+	"testing"
+)
+
+/*
+	A slice type which knows how to pass itself to a driver, and so must not be expanded.
+*/
+type valuerSlice []int
+
+func (this valuerSlice) Value() (driver.Value, error) {
+	return "{1,2}", nil
+}
+
+/*
+	Represents a single test of slice expansion.
+	Given a [Query] parsed with [Options], setting [Parameters] must produce
+	[Expected] and [ExpectedParameters], and Validate must fail only if [ExpectError] is set.
+*/
+type ExpansionTest struct {
+	Name string
+	Query string
+	Options QueryOptions
+	Parameters map[string]interface{}
+	Expected string
+	ExpectedParameters []interface{}
+	ExpectError bool
+}
+
+func TestSliceExpansion(test *testing.T) {
+
+	var query *NamedParameterQuery
+	var err error
+
+	expansionTests := []ExpansionTest {
+		ExpansionTest {
+			Name: "IntSlice",
+			Query: "SELECT * FROM table WHERE id IN (:ids) AND col1 = :foo",
+			Parameters: map[string]interface{} { "ids": []int { 1, 2, 3 }, "foo": "bar" },
+			Expected: "SELECT * FROM table WHERE id IN (?, ?, ?) AND col1 = ?",
+			ExpectedParameters: []interface{} { 1, 2, 3, "bar" },
+		},
+		ExpansionTest {
+			Name: "Array",
+			Query: "SELECT * FROM table WHERE id IN (:ids)",
+			Parameters: map[string]interface{} { "ids": [2]string { "a", "b" } },
+			Expected: "SELECT * FROM table WHERE id IN (?, ?)",
+			ExpectedParameters: []interface{} { "a", "b" },
+		},
+		ExpansionTest {
+			Name: "RepeatedSlice",
+			Query: "SELECT * FROM table WHERE id IN (:ids) OR parent IN (:ids)",
+			Parameters: map[string]interface{} { "ids": []int { 1, 2 } },
+			Expected: "SELECT * FROM table WHERE id IN (?, ?) OR parent IN (?, ?)",
+			ExpectedParameters: []interface{} { 1, 2, 1, 2 },
+		},
+		ExpansionTest {
+			Name: "NumberedRepeatedSlice",
+			Query: "SELECT * FROM table WHERE col1 = :foo AND id IN (:ids) OR parent IN (:ids)",
+			Options: QueryOptions { Dialect: PostgresDialect },
+			Parameters: map[string]interface{} { "ids": []int { 1, 2 }, "foo": "bar" },
+			Expected: "SELECT * FROM table WHERE col1 = $1 AND id IN ($2, $3) OR parent IN ($2, $3)",
+			ExpectedParameters: []interface{} { "bar", 1, 2 },
+		},
+		ExpansionTest {
+			Name: "Bytes",
+			Query: "SELECT * FROM table WHERE hash = :hash",
+			Parameters: map[string]interface{} { "hash": []byte { 1, 2 } },
+			Expected: "SELECT * FROM table WHERE hash = ?",
+			ExpectedParameters: nil,
+		},
+		ExpansionTest {
+			Name: "Valuer",
+			Query: "SELECT * FROM table WHERE tags = :tags",
+			Parameters: map[string]interface{} { "tags": valuerSlice { 1, 2 } },
+			Expected: "SELECT * FROM table WHERE tags = ?",
+			ExpectedParameters: nil,
+		},
+		ExpansionTest {
+			Name: "EmptySliceFails",
+			Query: "SELECT * FROM table WHERE id IN (:ids)",
+			Parameters: map[string]interface{} { "ids": []int {} },
+			Expected: "SELECT * FROM table WHERE id IN (NULL)",
+			ExpectedParameters: []interface{} {},
+			ExpectError: true,
+		},
+		ExpansionTest {
+			Name: "EmptySliceAsNull",
+			Query: "SELECT * FROM table WHERE id IN (:ids) AND col1 = :foo",
+			Options: QueryOptions { Dialect: OracleDialect, EmptySlices: EmptySlicesAsNull },
+			Parameters: map[string]interface{} { "ids": []int {}, "foo": "bar" },
+			Expected: "SELECT * FROM table WHERE id IN (NULL) AND col1 = :1",
+			ExpectedParameters: []interface{} { "bar" },
+		},
+	}
+
+	for _, expansionTest := range expansionTests {
+
+		query, err = ParseNamedParameterQueryWithOptions(expansionTest.Query, expansionTest.Options)
+		if(err != nil) {
+			test.Log("Test '", expansionTest.Name, "': Unexpected parse error: ", err)
+			test.Fail()
+			continue
+		}
+
+		query.SetValuesFromMap(expansionTest.Parameters)
+
+		if(query.GetParsedQuery() != expansionTest.Expected) {
+			test.Log("Test '", expansionTest.Name, "': Expected query text did not match actual parsed output")
+			test.Log("Actual: ", query.GetParsedQuery())
+			test.Fail()
+		}
+
+		// scalar-only tests can't compare uncomparable values, but must still produce a single parameter.
+		if(expansionTest.ExpectedParameters == nil) {
+			if(len(query.GetParsedParameters()) != 1) {
+				test.Log("Test '", expansionTest.Name, "': Expected the value to be passed as a single parameter")
+				test.Fail()
+			}
+		} else {
+			verifyStructParameters(expansionTest.Name, test, query, expansionTest.ExpectedParameters)
+		}
+
+		err = query.Validate()
+		if((err != nil) != expansionTest.ExpectError) {
+			test.Log("Test '", expansionTest.Name, "': Unexpected validation result: ", err)
+			test.Fail()
+		}
+	}
+}
+
+/*
+	Ensures that replacing a slice with a scalar (and back) is reflected in the parsed query.
+*/
+func TestSliceExpansionRebinding(test *testing.T) {
+
+	query := NewNamedParameterQuery("SELECT * FROM table WHERE id IN (:ids)")
+
+	query.SetValue("ids", []int { 1, 2 })
+	verifyStructParameters("SliceBinding", test, query, []interface{} { 1, 2 })
+
+	query.SetValue("ids", 3)
+	verifyStructParameters("ScalarRebinding", test, query, []interface{} { 3 })
+
+	if(query.GetParsedQuery() != "SELECT * FROM table WHERE id IN (?)") {
+		test.Log("Unexpected query after rebinding: ", query.GetParsedQuery())
+		test.Fail()
+	}
+
+	query.SetValue("ids", []int { 4, 5, 6 })
+	verifyStructParameters("SliceRebinding", test, query, []interface{} { 4, 5, 6 })
+}
+
+/*
+	Ensures that setting a scalar after the parameters were read is seen, even though expanding a slice
+	means the parameters were copied.
+*/
+func TestSliceExpansionScalarRebinding(test *testing.T) {
+
+	query := NewNamedParameterQuery("SELECT * FROM table WHERE id IN (:ids) AND name = :name")
+
+	query.SetValue("ids", []int { 1, 2 })
+	query.SetValue("name", "a")
+	verifyStructParameters("ScalarBinding", test, query, []interface{} { 1, 2, "a" })
+
+	query.SetValue("name", "b")
+	verifyStructParameters("ScalarRebinding", test, query, []interface{} { 1, 2, "b" })
+}
+
+func TestOptionalFragments(test *testing.T) {
+
+	var query *NamedParameterQuery
+	var err error
+
+	fragmentTests := []ExpansionTest {
+		ExpansionTest {
+			Name: "BoundFragment",
+			Query: "SELECT * FROM table WHERE 1 = 1/*[ AND status = :status]*//*[ AND name = :name]*/",
+			Options: QueryOptions { OptionalFragments: true },
+			Parameters: map[string]interface{} { "status": "open" },
+			Expected: "SELECT * FROM table WHERE 1 = 1 AND status = ?",
+			ExpectedParameters: []interface{} { "open" },
+		},
+		ExpansionTest {
+			Name: "EveryFragment",
+			Query: "SELECT * FROM table WHERE 1 = 1/*[ AND status = :status]*//*[ AND name = :name]*/",
+			Options: QueryOptions { OptionalFragments: true },
+			Parameters: map[string]interface{} { "status": "open", "name": "alice" },
+			Expected: "SELECT * FROM table WHERE 1 = 1 AND status = ? AND name = ?",
+			ExpectedParameters: []interface{} { "open", "alice" },
+		},
+		ExpansionTest {
+			Name: "NestedFragmentNeedsParent",
+			Query: "SELECT * FROM table WHERE a = :a/*[ AND b = :b/*[ AND c = :c]*/]*/ AND d = :a",
+			Options: QueryOptions { Dialect: PostgresDialect, OptionalFragments: true },
+			Parameters: map[string]interface{} { "a": 1, "c": 3 },
+			Expected: "SELECT * FROM table WHERE a = $1 AND d = $1",
+			ExpectedParameters: []interface{} { 1 },
+		},
+		ExpansionTest {
+			Name: "NestedFragmentLeftOut",
+			Query: "SELECT * FROM table WHERE a = :a/*[ AND b = :b/*[ AND c = :c]*/]*/ AND d = :a",
+			Options: QueryOptions { Dialect: PostgresDialect, OptionalFragments: true },
+			Parameters: map[string]interface{} { "a": 1, "b": 2 },
+			Expected: "SELECT * FROM table WHERE a = $1 AND b = $2 AND d = $1",
+			ExpectedParameters: []interface{} { 1, 2 },
+		},
+		ExpansionTest {
+			Name: "FragmentWithSlice",
+			Query: "SELECT * FROM table WHERE 1 = 1/*[ AND id IN (:ids)]*/ AND col1 = :foo",
+			Options: QueryOptions { OptionalFragments: true },
+			Parameters: map[string]interface{} { "ids": []int { 1, 2 }, "foo": "bar" },
+			Expected: "SELECT * FROM table WHERE 1 = 1 AND id IN (?, ?) AND col1 = ?",
+			ExpectedParameters: []interface{} { 1, 2, "bar" },
+		},
+		ExpansionTest {
+			Name: "RequiredOutsideFragment",
+			Query: "SELECT * FROM table WHERE col1 = :foo/*[ AND col2 = :foo]*/",
+			Options: QueryOptions { OptionalFragments: true },
+			Parameters: map[string]interface{} {},
+			Expected: "SELECT * FROM table WHERE col1 = ?",
+			ExpectedParameters: []interface{} { nil },
+			ExpectError: true,
+		},
+		ExpansionTest {
+			Name: "FragmentsDisabled",
+			Query: "SELECT * FROM table /*[ AND col1 = :foo ]*/",
+			Parameters: map[string]interface{} { "foo": 1 },
+			Expected: "SELECT * FROM table /*[ AND col1 = :foo ]*/",
+			ExpectedParameters: []interface{} {},
+		},
+	}
+
+	for _, fragmentTest := range fragmentTests {
+
+		query, err = ParseNamedParameterQueryWithOptions(fragmentTest.Query, fragmentTest.Options)
+		if(err != nil) {
+			test.Log("Test '", fragmentTest.Name, "': Unexpected parse error: ", err)
+			test.Fail()
+			continue
+		}
+
+		query.SetValuesFromMap(fragmentTest.Parameters)
+
+		if(query.GetParsedQuery() != fragmentTest.Expected) {
+			test.Log("Test '", fragmentTest.Name, "': Expected query text did not match actual parsed output")
+			test.Log("Actual: ", query.GetParsedQuery())
+			test.Fail()
+		}
+
+		verifyStructParameters(fragmentTest.Name, test, query, fragmentTest.ExpectedParameters)
+
+		err = query.Validate()
+		if((err != nil) != fragmentTest.ExpectError) {
+			test.Log("Test '", fragmentTest.Name, "': Unexpected validation result: ", err)
+			test.Fail()
+		}
+	}
+}
@@ -0,0 +1,218 @@
+package namedParameterQuery
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"sync"
+)
+
+/*
+	fakeDriver is a database driver which records every statement it's asked to run,
+	and answers every query with the same canned rows.
+	Like most real drivers, it only understands positional parameters.
+*/
+type fakeDriver struct {
+	lock sync.Mutex
+
+	// Every statement run, in order.
+	statements []fakeStatement
+
+	// The number of times a statement was prepared.
+	prepared int
+
+	// The result of every query.
+	columns []string
+	rows [][]driver.Value
+
+	// If set, connections can run queries directly, without preparing them first.
+	direct bool
+}
+
+/*
+	A single statement run by a fakeDriver.
+*/
+type fakeStatement struct {
+	query string
+	args []driver.Value
+}
+
+type fakeConn struct {
+	driver *fakeDriver
+}
+
+/*
+	fakeDirectConn is a fakeConn which implements QueryerContext and ExecerContext.
+*/
+type fakeDirectConn struct {
+	*fakeConn
+}
+
+type fakeStmt struct {
+	conn *fakeConn
+	query string
+}
+
+type fakeTx struct{}
+
+type fakeRows struct {
+	columns []string
+	rows [][]driver.Value
+	index int
+}
+
+/*
+	openFakeDB returns a *sql.DB backed by a new fakeDriver, which will answer queries with the given [columns] and [rows].
+*/
+func openFakeDB(columns []string, rows ...[]driver.Value) (*sql.DB, *fakeDriver) {
+
+	fake := &fakeDriver { columns: columns, rows: rows }
+	return sql.OpenDB(fake), fake
+}
+
+func (this *fakeDriver) Open(name string) (driver.Conn, error) {
+
+	if(this.direct) {
+		return fakeDirectConn { &fakeConn { driver: this } }, nil
+	}
+	return &fakeConn { driver: this }, nil
+}
+
+func (this *fakeDriver) Connect(ctx context.Context) (driver.Conn, error) {
+	return this.Open("")
+}
+
+func (this *fakeDriver) Driver() (driver.Driver) {
+	return this
+}
+
+func (this *fakeDriver) record(query string, args []driver.Value) {
+
+	this.lock.Lock()
+	defer this.lock.Unlock()
+
+	this.statements = append(this.statements, fakeStatement { query: query, args: args })
+}
+
+/*
+	last returns the most recently run statement.
+*/
+func (this *fakeDriver) last() (fakeStatement) {
+
+	this.lock.Lock()
+	defer this.lock.Unlock()
+
+	if(len(this.statements) == 0) {
+		return fakeStatement{}
+	}
+	return this.statements[len(this.statements) - 1]
+}
+
+func (this *fakeConn) Prepare(query string) (driver.Stmt, error) {
+
+	this.driver.lock.Lock()
+	this.driver.prepared++
+	this.driver.lock.Unlock()
+
+	return &fakeStmt { conn: this, query: query }, nil
+}
+
+func (this fakeDirectConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+
+	values, err := fakePositionalValues(args)
+	if(err != nil) {
+		return nil, err
+	}
+
+	this.driver.record(query, values)
+	return &fakeRows { columns: this.driver.columns, rows: this.driver.rows }, nil
+}
+
+func (this fakeDirectConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+
+	values, err := fakePositionalValues(args)
+	if(err != nil) {
+		return nil, err
+	}
+
+	this.driver.record(query, values)
+	return driver.RowsAffected(1), nil
+}
+
+/*
+	fakePositionalValues rejects named arguments, as most drivers do.
+*/
+func fakePositionalValues(args []driver.NamedValue) ([]driver.Value, error) {
+
+	values := make([]driver.Value, len(args))
+
+	for index, arg := range args {
+
+		if(len(arg.Name) > 0) {
+			return nil, errors.New("fake driver does not support named arguments")
+		}
+		values[index] = arg.Value
+	}
+	return values, nil
+}
+
+func (this *fakeConn) Close() (error) {
+	return nil
+}
+
+func (this *fakeConn) Begin() (driver.Tx, error) {
+	return fakeTx{}, nil
+}
+
+func (this fakeTx) Commit() (error) {
+	return nil
+}
+
+func (this fakeTx) Rollback() (error) {
+	return nil
+}
+
+func (this *fakeStmt) Close() (error) {
+	return nil
+}
+
+func (this *fakeStmt) NumInput() (int) {
+	return -1
+}
+
+func (this *fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+
+	this.conn.driver.record(this.query, args)
+	return driver.RowsAffected(1), nil
+}
+
+func (this *fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+
+	this.conn.driver.record(this.query, args)
+	return &fakeRows { columns: this.conn.driver.columns, rows: this.conn.driver.rows }, nil
+}
+
+func (this *fakeRows) Columns() ([]string) {
+	return this.columns
+}
+
+func (this *fakeRows) Close() (error) {
+	return nil
+}
+
+func (this *fakeRows) Next(dest []driver.Value) (error) {
+
+	if(this.index >= len(this.rows)) {
+		return io.EOF
+	}
+
+	if(len(dest) != len(this.rows[this.index])) {
+		return errors.New("fake row does not match its columns")
+	}
+
+	copy(dest, this.rows[this.index])
+	this.index++
+	return nil
+}
@@ -0,0 +1,76 @@
+package namedParameterQuery
+
+import (
+	"reflect"
+	"unicode"
+)
+
+/*
+	IdentifierPolicy decides which characters may be part of a parameter name.
+	A parameter name is every character following its prefix for which the policy returns true.
+
+	The policy is given to ParseNamedParameterQueryWithOptions through QueryOptions.
+	Any func can be used, but the built-in policies should cover most cases.
+*/
+type IdentifierPolicy func(character rune) bool
+
+/*
+	DefaultIdentifiers allows letters, digits, and underscores in parameter names, e.g. ":user_id".
+*/
+func DefaultIdentifiers(character rune) bool {
+	return character == '_' || unicode.IsLetter(character) || unicode.IsDigit(character)
+}
+
+/*
+	ASCIIIdentifiers allows only ASCII letters, digits, and underscores in parameter names.
+	A parameter name which runs into any other letter is reported as an InvalidParameterName.
+*/
+func ASCIIIdentifiers(character rune) bool {
+
+	return character == '_' ||
+		(character >= 'a' && character <= 'z') ||
+		(character >= 'A' && character <= 'Z') ||
+		(character >= '0' && character <= '9')
+}
+
+/*
+	DottedIdentifiers allows everything DefaultIdentifiers does, plus dots, for path-like names such as ":user.id".
+*/
+func DottedIdentifiers(character rune) bool {
+	return character == '.' || DefaultIdentifiers(character)
+}
+
+/*
+	builtinIdentifierPolicies identifies the built-in policies by their code pointer.
+	Funcs can't be compared, but each of these is a plain function, so the pointer identifies it exactly;
+	the same can't be said of closures, which is why custom policies aren't included.
+*/
+var builtinIdentifierPolicies = map[uintptr]bool {
+	reflect.ValueOf(DefaultIdentifiers).Pointer(): true,
+	reflect.ValueOf(ASCIIIdentifiers).Pointer(): true,
+	reflect.ValueOf(DottedIdentifiers).Pointer(): true,
+}
+
+/*
+	ParameterPrefix selects which syntaxes mark named parameters in a query.
+	Prefixes can be combined, e.g. "ColonPrefix | AtPrefix" recognizes both ":name" and "@name".
+*/
+type ParameterPrefix int
+
+const (
+
+	// ":name", the default.
+	ColonPrefix ParameterPrefix = 1 << iota
+
+	// "@name", as used by SQL Server. System variables such as "@@rowcount" are left alone.
+	AtPrefix
+
+	// "$name". Dollar-quoted strings are still recognized in dialects which use them.
+	DollarPrefix
+
+	// "${name}", as used by templating systems. The name may contain any character except "}".
+	DollarBracePrefix
+
+	// "#{name}", as used by MyBatis. The name may contain any character except "}".
+	HashBracePrefix
+)
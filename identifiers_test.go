@@ -0,0 +1,186 @@
+package namedParameterQuery
+
+import (
+	"testing"
+)
+
+/*
+	Represents a single test of an identifier policy.
+	Given an [Input] query parsed with [Identifiers], the parsed query must match [Expected],
+	and the named parameters must be exactly [ExpectedNames].
+*/
+type IdentifierPolicyTest struct {
+	Name string
+	Identifiers IdentifierPolicy
+	Input string
+	Expected string
+	ExpectedNames []string
+}
+
+func TestIdentifierPolicies(test *testing.T) {
+
+	identifierTests := []IdentifierPolicyTest {
+		IdentifierPolicyTest {
+			Name: "DefaultUnderscore",
+			Input: "SELECT * FROM table WHERE col1 = :user_id AND col2 = :_private",
+			Expected: "SELECT * FROM table WHERE col1 = ? AND col2 = ?",
+			ExpectedNames: []string { "user_id", "_private" },
+		},
+		IdentifierPolicyTest {
+			Name: "DefaultUnicode",
+			Identifiers: DefaultIdentifiers,
+			Input: "SELECT * FROM table WHERE col1 = :café",
+			Expected: "SELECT * FROM table WHERE col1 = ?",
+			ExpectedNames: []string { "café" },
+		},
+		IdentifierPolicyTest {
+			Name: "DefaultStopsAtDot",
+			Input: "SELECT * FROM table t WHERE t.col1 = :col1.",
+			Expected: "SELECT * FROM table t WHERE t.col1 = ?.",
+			ExpectedNames: []string { "col1" },
+		},
+		IdentifierPolicyTest {
+			Name: "Dotted",
+			Identifiers: DottedIdentifiers,
+			Input: "SELECT * FROM table WHERE col1 = :user.id AND col2 = :user.address.city",
+			Expected: "SELECT * FROM table WHERE col1 = ? AND col2 = ?",
+			ExpectedNames: []string { "user.id", "user.address.city" },
+		},
+		IdentifierPolicyTest {
+			Name: "ASCII",
+			Identifiers: ASCIIIdentifiers,
+			Input: "SELECT * FROM table WHERE col1 = :user_id",
+			Expected: "SELECT * FROM table WHERE col1 = ?",
+			ExpectedNames: []string { "user_id" },
+		},
+		IdentifierPolicyTest {
+			Name: "Custom",
+			Identifiers: func(character rune) bool {
+				return character == '-' || DefaultIdentifiers(character)
+			},
+			Input: "SELECT * FROM table WHERE col1 = :user-id",
+			Expected: "SELECT * FROM table WHERE col1 = ?",
+			ExpectedNames: []string { "user-id" },
+		},
+	}
+
+	for _, identifierTest := range identifierTests {
+
+		query, err := ParseNamedParameterQueryWithOptions(identifierTest.Input, QueryOptions { Identifiers: identifierTest.Identifiers })
+
+		if(err != nil) {
+			test.Log("Test '", identifierTest.Name, "': Unexpected parse error: ", err)
+			test.Fail()
+			continue
+		}
+
+		if(query.GetParsedQuery() != identifierTest.Expected) {
+			test.Log("Test '", identifierTest.Name, "': Expected query text did not match actual parsed output")
+			test.Log("Actual: ", query.GetParsedQuery())
+			test.Fail()
+		}
+
+		if(len(query.template.positions) != len(identifierTest.ExpectedNames)) {
+			test.Log("Test '", identifierTest.Name, "': Expected ", len(identifierTest.ExpectedNames), " parameter names, got ", len(query.template.positions))
+			test.Fail()
+		}
+
+		for _, name := range identifierTest.ExpectedNames {
+
+			if(len(query.template.positions[name]) == 0) {
+				test.Log("Test '", identifierTest.Name, "': Expected parameter '", name, "' was not found")
+				test.Fail()
+			}
+		}
+	}
+}
+
+/*
+	Represents a single test of parameter prefixes.
+	Given an [Input] query parsed with [Prefixes], the parsed query must match [Expected],
+	and the named parameters must be exactly [ExpectedNames].
+*/
+type ParameterPrefixTest struct {
+	Name string
+	Prefixes ParameterPrefix
+	Input string
+	Expected string
+	ExpectedNames []string
+}
+
+func TestParameterPrefixes(test *testing.T) {
+
+	prefixTests := []ParameterPrefixTest {
+		ParameterPrefixTest {
+			Name: "DefaultIsColon",
+			Input: "SELECT * FROM table WHERE col1 = :foo AND col2 = @bar AND col3 = ${baz}",
+			Expected: "SELECT * FROM table WHERE col1 = ? AND col2 = @bar AND col3 = ${baz}",
+			ExpectedNames: []string { "foo" },
+		},
+		ParameterPrefixTest {
+			Name: "At",
+			Prefixes: AtPrefix,
+			Input: "SELECT @@ROWCOUNT FROM table WHERE col1 = @foo AND col2 = :bar",
+			Expected: "SELECT @@ROWCOUNT FROM table WHERE col1 = ? AND col2 = :bar",
+			ExpectedNames: []string { "foo" },
+		},
+		ParameterPrefixTest {
+			Name: "Dollar",
+			Prefixes: DollarPrefix,
+			Input: "SELECT foo$bar FROM table WHERE col1 = $foo",
+			Expected: "SELECT foo$bar FROM table WHERE col1 = ?",
+			ExpectedNames: []string { "foo" },
+		},
+		ParameterPrefixTest {
+			Name: "DollarBrace",
+			Prefixes: DollarBracePrefix,
+			Input: "SELECT * FROM table WHERE col1 = ${user id} AND col2 = ${ spaced }",
+			Expected: "SELECT * FROM table WHERE col1 = ? AND col2 = ?",
+			ExpectedNames: []string { "user id", "spaced" },
+		},
+		ParameterPrefixTest {
+			Name: "HashBrace",
+			Prefixes: HashBracePrefix,
+			Input: "SELECT * FROM table WHERE col1 = #{user.id} AND col2 = '#{literal}'",
+			Expected: "SELECT * FROM table WHERE col1 = ? AND col2 = '#{literal}'",
+			ExpectedNames: []string { "user.id" },
+		},
+		ParameterPrefixTest {
+			Name: "Combined",
+			Prefixes: ColonPrefix | AtPrefix | HashBracePrefix,
+			Input: "SELECT * FROM table WHERE col1 = :foo AND col2 = @bar AND col3 = #{baz}",
+			Expected: "SELECT * FROM table WHERE col1 = ? AND col2 = ? AND col3 = ?",
+			ExpectedNames: []string { "foo", "bar", "baz" },
+		},
+	}
+
+	for _, prefixTest := range prefixTests {
+
+		query, err := ParseNamedParameterQueryWithOptions(prefixTest.Input, QueryOptions { Prefixes: prefixTest.Prefixes })
+
+		if(err != nil) {
+			test.Log("Test '", prefixTest.Name, "': Unexpected parse error: ", err)
+			test.Fail()
+			continue
+		}
+
+		if(query.GetParsedQuery() != prefixTest.Expected) {
+			test.Log("Test '", prefixTest.Name, "': Expected query text did not match actual parsed output")
+			test.Log("Actual: ", query.GetParsedQuery())
+			test.Fail()
+		}
+
+		if(len(query.template.positions) != len(prefixTest.ExpectedNames)) {
+			test.Log("Test '", prefixTest.Name, "': Expected ", len(prefixTest.ExpectedNames), " parameter names, got ", len(query.template.positions))
+			test.Fail()
+		}
+
+		for _, name := range prefixTest.ExpectedNames {
+
+			if(len(query.template.positions[name]) == 0) {
+				test.Log("Test '", prefixTest.Name, "': Expected parameter '", name, "' was not found")
+				test.Fail()
+			}
+		}
+	}
+}
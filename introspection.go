@@ -0,0 +1,102 @@
+package namedParameterQuery
+
+/*
+	Occurrence describes a single appearance of a named parameter in a query.
+*/
+type Occurrence struct {
+
+	// The zero-based index of the positional parameter which this occurrence was written as,
+	// before any slice values are expanded. In dialects which reuse placeholders,
+	// every occurrence of a parameter has the same index.
+	Position int
+
+	// The byte offset of this occurrence (including its prefix, e.g. the ":" of ":name") in the original query.
+	Offset int
+
+	// The text of this occurrence in the original query, e.g. ":name" or "${name}".
+	Text string
+}
+
+/*
+	ParameterNames returns the name of every parameter in this query, once each, in the order they first appear.
+	Names are given as mapped by the query's NameMapper.
+*/
+func (this *QueryTemplate) ParameterNames() ([]string) {
+	return append([]string(nil), this.names...)
+}
+
+/*
+	IdentifierNames returns the name of every identifier parameter (e.g. ":!column") in this query, once each,
+	in the order they first appear. Names are given as mapped by the query's NameMapper.
+*/
+func (this *QueryTemplate) IdentifierNames() ([]string) {
+	return append([]string(nil), this.identifierNames...)
+}
+
+/*
+	Occurrences returns every appearance of the parameter with the given [name] in this query, in order,
+	or nil if this query has no such parameter.
+*/
+func (this *QueryTemplate) Occurrences(name string) ([]Occurrence) {
+
+	var ret []Occurrence
+	var positions []int
+	var count int
+
+	name = this.options.Names(name)
+	positions = this.positions[name]
+
+	if(len(positions) == 0) {
+		return nil
+	}
+
+	ret = make([]Occurrence, 0, len(positions))
+
+	for _, segment := range this.segments {
+
+		if(segment.kind != parameterSegment || segment.name != name) {
+			continue
+		}
+
+		// every occurrence has its own position, unless the dialect reuses the first.
+		ret = append(ret, Occurrence {
+			Position: positions[minimum(count, len(positions) - 1)],
+			Offset: segment.offset,
+			Text: segment.text,
+		})
+		count++
+	}
+	return ret
+}
+
+/*
+	ParameterNames returns the name of every parameter in this binding's query. See QueryTemplate.ParameterNames.
+*/
+func (this *Binding) ParameterNames() ([]string) {
+	return this.template.ParameterNames()
+}
+
+/*
+	Occurrences returns every appearance of the given parameter in this binding's query. See QueryTemplate.Occurrences.
+*/
+func (this *Binding) Occurrences(name string) ([]Occurrence) {
+	return this.template.Occurrences(name)
+}
+
+/*
+	IsBound returns true if the parameter with the given [name] has had a value set.
+	If this query has no such parameter, false is returned.
+*/
+func (this *Binding) IsBound(name string) (bool) {
+
+	_, found := this.value(this.template.options.Names(name))
+	return found
+}
+
+func minimum(left int, right int) (int) {
+
+	if(left < right) {
+		return left
+	}
+	return right
+}
@@ -0,0 +1,69 @@
+package namedParameterQuery
+
+import (
+	"testing"
+)
+
+func TestParameterNames(test *testing.T) {
+
+	query := NewNamedParameterQuery("SELECT * FROM table WHERE col1 = :foo AND col2 = :bar AND col3 = :foo AND col4 = ':baz'")
+
+	if(!equalNames(query.ParameterNames(), []string { "foo", "bar" })) {
+		test.Log("Unexpected parameter names: ", query.ParameterNames())
+		test.Fail()
+	}
+
+	if(len(NewNamedParameterQuery("SELECT 1").ParameterNames()) != 0) {
+		test.Log("Expected a query without parameters to have no parameter names")
+		test.Fail()
+	}
+}
+
+func TestOccurrences(test *testing.T) {
+
+	var occurrences []Occurrence
+
+	queryText := "SELECT * FROM table WHERE col1 = :foo AND col2 = :bar AND col3 = :foo"
+
+	occurrences = NewNamedParameterQuery(queryText).Occurrences("foo")
+	verifyOccurrences("MySQL", test, occurrences, []Occurrence {
+		Occurrence { Position: 0, Offset: 33, Text: ":foo" },
+		Occurrence { Position: 2, Offset: 65, Text: ":foo" },
+	})
+
+	occurrences = NewNamedParameterQueryWithDialect(queryText, PostgresDialect).Occurrences("foo")
+	verifyOccurrences("Postgres", test, occurrences, []Occurrence {
+		Occurrence { Position: 0, Offset: 33, Text: ":foo" },
+		Occurrence { Position: 0, Offset: 65, Text: ":foo" },
+	})
+
+	occurrences = NewNamedParameterQuery(queryText).Occurrences("baz")
+	verifyOccurrences("Missing", test, occurrences, nil)
+}
+
+func TestIsBound(test *testing.T) {
+
+	query := NewNamedParameterQuery("SELECT * FROM table WHERE col1 = :foo AND col2 = :bar")
+	query.SetValue("foo", nil)
+
+	if(!query.IsBound("foo") || query.IsBound("bar") || query.IsBound("baz")) {
+		test.Log("Expected only 'foo' to be bound")
+		test.Fail()
+	}
+}
+
+func verifyOccurrences(testName string, test *testing.T, actual []Occurrence, expected []Occurrence) {
+
+	if(len(actual) != len(expected)) {
+		test.Log("Test '", testName, "': Expected ", len(expected), " occurrences, got: ", actual)
+		test.Fail()
+		return
+	}
+
+	for index, occurrence := range actual {
+		if(occurrence != expected[index]) {
+			test.Log("Test '", testName, "': Occurrence ", index, " (", occurrence, ") did not match expected (", expected[index], ")")
+			test.Fail()
+		}
+	}
+}
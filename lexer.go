@@ -0,0 +1,546 @@
+package namedParameterQuery
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+/*
+	Represents the kind of region of a query that a token covers.
+*/
+type tokenKind int
+
+const (
+
+	// Plain SQL, which is copied as-is into the parsed query.
+	codeToken tokenKind = iota
+
+	// A named parameter. The token's value is the parameter name.
+	parameterToken
+
+	// A quoted string literal, including dollar-quoted bodies.
+	stringToken
+
+	// A quoted identifier, such as "name", `name`, or [name].
+	identifierToken
+
+	// A line or block comment.
+	commentToken
+
+	// The start ("/*[") or end ("]*/") of an optional fragment.
+	optionalStartToken
+	optionalEndToken
+
+	// A named identifier parameter, e.g. ":!column". The token's value is the parameter name.
+	identifierParameterToken
+
+	// A slot into which another query can be composed, e.g. "{{filter}}". The token's value is the slot name.
+	slotToken
+)
+
+/*
+	A token is a single region of a query, as produced by lexQuery.
+	Concatenating the [text] of every token reproduces the original query exactly.
+*/
+type token struct {
+	kind tokenKind
+
+	// The verbatim text of this token in the original query.
+	text string
+
+	// For parameter tokens, the name of the parameter.
+	value string
+
+	// The byte offset of this token in the original query.
+	offset int
+}
+
+/*
+	lexer splits a query into tokens, so that named parameters are only recognized
+	where they are actually part of the SQL, and never inside strings, quoted identifiers, or comments.
+*/
+type lexer struct {
+	query string
+	syntax SyntaxRules
+	identifiers IdentifierPolicy
+	prefixes ParameterPrefix
+	fragments bool
+	slots bool
+
+	// The byte offsets of the start of every optional fragment which hasn't ended yet.
+	openFragments []int
+
+	// The current byte offset into [query]
+	position int
+
+	// The byte offset at which the current run of plain SQL started.
+	codeStart int
+
+	tokens []token
+
+	// The first problem found in the query, if any.
+	err *ParseError
+}
+
+/*
+	lexQuery splits the given [query] into tokens according to the given [options]; the syntax of their dialect,
+	their parameter prefixes, their identifier policy (which decides where parameter names end),
+	and whether or not they allow optional fragments and slots.
+
+	If the query is malformed, the first problem is returned as a *ParseError, but lexing carries on regardless;
+	unterminated strings, identifiers, and comments run to the end of the query,
+	and a parameter prefix without a name is treated as plain SQL.
+*/
+func lexQuery(query string, options QueryOptions) ([]token, error) {
+
+	var state lexer
+
+	state.query = query
+	state.syntax = options.Dialect.Syntax()
+	state.identifiers = options.Identifiers
+	state.prefixes = options.Prefixes
+	state.fragments = options.OptionalFragments
+	state.slots = options.Slots
+	state.tokens = make([]token, 0, 8)
+	state.run()
+
+	if(state.err != nil) {
+		return state.tokens, state.err
+	}
+	return state.tokens, nil
+}
+
+func (this *lexer) run() {
+
+	var character byte
+	var next byte
+
+	for this.position < len(this.query) {
+
+		character = this.query[this.position]
+		next = this.peek(1)
+
+		switch {
+		case character == '\'':
+			this.scanQuoted(stringToken, '\'', this.syntax.BackslashEscapes || this.isEscapeString())
+
+		case character == '"':
+			this.scanQuoted(identifierToken, '"', this.syntax.BackslashEscapes)
+
+		case character == '`' && this.syntax.BacktickIdentifiers:
+			this.scanQuoted(identifierToken, '`', false)
+
+		case character == '[' && this.syntax.BracketIdentifiers:
+			this.scanQuoted(identifierToken, ']', false)
+
+		case character == '/' && next == '*' && this.peek(2) == '[' && this.fragments:
+			this.openFragments = append(this.openFragments, this.position)
+			this.emit(optionalStartToken, this.position + 3, "")
+
+		case character == ']' && next == '*' && this.peek(2) == '/' && len(this.openFragments) > 0:
+			this.openFragments = this.openFragments[:len(this.openFragments) - 1]
+			this.emit(optionalEndToken, this.position + 3, "")
+
+		case character == '-' && next == '-':
+			this.scanLineComment()
+
+		case character == '/' && next == '*':
+			this.scanBlockComment()
+
+		case character == '$' && this.syntax.DollarQuotes && this.dollarTag() != "":
+			this.scanDollarQuoted()
+
+		case character == '$' && next == '{' && this.prefixes & DollarBracePrefix != 0:
+			this.scanBracedParameter()
+
+		case character == '#' && next == '{' && this.prefixes & HashBracePrefix != 0:
+			this.scanBracedParameter()
+
+		// checked after "#{", so that hash-brace parameters still work in dialects with hash comments.
+		case character == '#' && this.syntax.HashComments:
+			this.scanLineComment()
+
+		case character == '{' && next == '{' && this.slots:
+			this.scanSlot()
+
+		case character == '$' && this.prefixes & DollarPrefix != 0 && !this.followsWord():
+			this.scanParameter()
+
+		// system variables ("@@version") are plain SQL, not parameters.
+		case character == '@' && next == '@':
+			this.position += 2
+
+		case character == '@' && this.prefixes & AtPrefix != 0:
+			this.scanParameter()
+
+		// casts ("::type") and assignments (":=") are plain SQL, not parameters.
+		case character == ':' && (next == ':' || next == '='):
+			this.position += 2
+
+		// array slices ("arr[1:2]", "arr[lo:hi]") are plain SQL too.
+		case character == ':' && this.syntax.ArraySlices && this.isSliceColon():
+			this.position++
+
+		case character == ':' && this.prefixes & ColonPrefix != 0:
+			this.scanParameter()
+
+		default:
+			this.position++
+		}
+	}
+
+	this.flushCode()
+
+	// an unterminated fragment runs to the end of the query.
+	if(len(this.openFragments) > 0) {
+		this.fail(UnterminatedFragment, this.openFragments[0])
+	}
+}
+
+/*
+	peek returns the byte [distance] bytes ahead of the current position, or zero past the end of the query.
+*/
+func (this *lexer) peek(distance int) byte {
+
+	if(this.position + distance >= len(this.query)) {
+		return 0
+	}
+	return this.query[this.position + distance]
+}
+
+/*
+	fail records a problem of the given [kind] at the given byte [offset], unless an earlier problem was already found.
+*/
+func (this *lexer) fail(kind ParseErrorKind, offset int) {
+
+	if(this.err == nil) {
+		this.err = newParseError(kind, this.query, offset)
+	}
+}
+
+/*
+	flushCode emits any pending run of plain SQL as a code token.
+*/
+func (this *lexer) flushCode() {
+
+	if(this.codeStart < this.position) {
+		this.tokens = append(this.tokens, token {
+			kind: codeToken,
+			text: this.query[this.codeStart:this.position],
+			offset: this.codeStart,
+		})
+	}
+	this.codeStart = this.position
+}
+
+/*
+	emit flushes pending code, then emits a token of the given [kind] spanning from the current position to [end].
+*/
+func (this *lexer) emit(kind tokenKind, end int, value string) {
+
+	this.flushCode()
+	this.tokens = append(this.tokens, token {
+		kind: kind,
+		text: this.query[this.position:end],
+		value: value,
+		offset: this.position,
+	})
+
+	this.position = end
+	this.codeStart = end
+}
+
+/*
+	scanQuoted emits a token for a region opened at the current position and closed by [terminator].
+	A doubled terminator is an escaped terminator; if [backslashEscapes] is set, so is a backslash-prefixed one.
+*/
+func (this *lexer) scanQuoted(kind tokenKind, terminator byte, backslashEscapes bool) {
+
+	var character byte
+
+	for i := this.position + 1; i < len(this.query); i++ {
+
+		character = this.query[i]
+
+		if(backslashEscapes && character == '\\') {
+			i++
+			continue
+		}
+
+		if(character == terminator) {
+
+			if(i + 1 < len(this.query) && this.query[i + 1] == terminator) {
+				i++
+				continue
+			}
+
+			this.emit(kind, i + 1, "")
+			return
+		}
+	}
+
+	if(kind == identifierToken) {
+		this.fail(UnterminatedIdentifier, this.position)
+	} else {
+		this.fail(UnterminatedString, this.position)
+	}
+	this.emit(kind, len(this.query), "")
+}
+
+/*
+	isEscapeString returns true if the quote at the current position begins a Postgres E'' string.
+*/
+func (this *lexer) isEscapeString() bool {
+
+	if(!this.syntax.EscapeStrings || this.position == 0) {
+		return false
+	}
+
+	prefix := this.query[this.position - 1]
+	if(prefix != 'E' && prefix != 'e') {
+		return false
+	}
+
+	// the E must stand alone, not be the end of a longer word.
+	return this.position < 2 || !isWordByte(this.query[this.position - 2])
+}
+
+func (this *lexer) scanLineComment() {
+
+	end := strings.IndexByte(this.query[this.position:], '\n')
+
+	if(end < 0) {
+		this.emit(commentToken, len(this.query), "")
+		return
+	}
+	this.emit(commentToken, this.position + end, "")
+}
+
+func (this *lexer) scanBlockComment() {
+
+	var depth int
+
+	depth = 0
+
+	for i := this.position; i + 1 < len(this.query); i++ {
+
+		if(this.query[i] == '/' && this.query[i + 1] == '*') {
+
+			if(depth == 0 || this.syntax.NestedComments) {
+				depth++
+			}
+			i++
+			continue
+		}
+
+		if(this.query[i] == '*' && this.query[i + 1] == '/') {
+
+			depth--
+			i++
+
+			if(depth == 0) {
+				this.emit(commentToken, i + 1, "")
+				return
+			}
+		}
+	}
+
+	this.fail(UnterminatedComment, this.position)
+	this.emit(commentToken, len(this.query), "")
+}
+
+/*
+	dollarTag returns the opening tag of a dollar-quoted string at the current position (e.g. "$$" or "$body$"),
+	or an empty string if the current position does not start one.
+*/
+func (this *lexer) dollarTag() string {
+
+	var character byte
+
+	// a dollar inside a word (e.g. "foo$bar") is part of an identifier.
+	if(this.followsWord()) {
+		return ""
+	}
+
+	for i := this.position + 1; i < len(this.query); i++ {
+
+		character = this.query[i]
+
+		if(character == '$') {
+			return this.query[this.position:i + 1]
+		}
+
+		// tags follow identifier rules, so "$1" is a positional parameter rather than a tag.
+		if(!isWordByte(character) || (i == this.position + 1 && character >= '0' && character <= '9')) {
+			return ""
+		}
+	}
+	return ""
+}
+
+/*
+	followsWord returns true if the byte before the current position is part of a word.
+*/
+func (this *lexer) followsWord() bool {
+	return this.position > 0 && isWordByte(this.query[this.position - 1])
+}
+
+/*
+	isSliceColon returns true if the colon at the current position separates the bounds of an array slice,
+	because it follows a word (e.g. "arr[1:2]"), or opens a subscript and is followed by a number or its end
+	(e.g. "arr[:2]" or "arr[:]"). A colon which opens a subscript otherwise starts a parameter, as in "ARRAY[:a]".
+*/
+func (this *lexer) isSliceColon() bool {
+
+	if(this.position == 0) {
+		return false
+	}
+
+	previous := this.query[this.position - 1]
+	next := this.peek(1)
+
+	if(previous == '[') {
+		return next == ']' || (next >= '0' && next <= '9')
+	}
+	return previous == ']' || isWordByte(previous)
+}
+
+func (this *lexer) scanDollarQuoted() {
+
+	tag := this.dollarTag()
+	end := strings.Index(this.query[this.position + len(tag):], tag)
+
+	if(end < 0) {
+		this.fail(UnterminatedString, this.position)
+		this.emit(stringToken, len(this.query), "")
+		return
+	}
+	this.emit(stringToken, this.position + len(tag) + end + len(tag), "")
+}
+
+/*
+	scanParameter emits a parameter token for the single-character prefix at the current position,
+	using every following name character as the parameter's name.
+	If the prefix is followed by "!", the token is an identifier parameter instead.
+	A prefix which is not followed by a name is left as plain SQL.
+*/
+func (this *lexer) scanParameter() {
+
+	var character rune
+	var width int
+	var start int
+	var end int
+	var kind tokenKind
+
+	kind = parameterToken
+	start = this.position + 1
+
+	if(this.peek(1) == '!') {
+		kind = identifierParameterToken
+		start++
+	}
+
+	end = start
+
+	for end < len(this.query) {
+
+		character, width = utf8.DecodeRuneInString(this.query[end:])
+
+		if(!this.identifiers(character)) {
+			break
+		}
+		end += width
+	}
+
+	if(end == start) {
+		this.fail(EmptyParameterName, this.position)
+		this.position = end
+		return
+	}
+
+	// a name which stops at something that looks like more of the name would silently bind the wrong parameter.
+	character, _ = utf8.DecodeRuneInString(this.query[end:])
+	if(end < len(this.query) && isWordRune(character)) {
+		this.fail(InvalidParameterName, end)
+	}
+
+	this.emit(kind, end, this.query[start:end])
+}
+
+/*
+	scanBracedParameter emits a parameter token for the two-character prefix (e.g. "${") at the current position.
+	Everything up to the closing brace is the parameter's name, regardless of the identifier policy.
+*/
+func (this *lexer) scanBracedParameter() {
+
+	var end int
+	var name string
+
+	end = strings.IndexByte(this.query[this.position + 2:], '}')
+
+	if(end < 0) {
+		this.fail(UnterminatedParameter, this.position)
+		this.position = len(this.query)
+		return
+	}
+
+	end += this.position + 2
+	name = strings.TrimSpace(this.query[this.position + 2:end])
+
+	if(len(name) == 0) {
+		this.fail(EmptyParameterName, this.position)
+		this.position = end + 1
+		return
+	}
+
+	this.emit(parameterToken, end + 1, name)
+}
+
+/*
+	scanSlot emits a slot token for the "{{" at the current position. Everything up to the closing "}}" is the slot's name.
+*/
+func (this *lexer) scanSlot() {
+
+	var end int
+	var name string
+
+	end = strings.Index(this.query[this.position + 2:], "}}")
+
+	if(end < 0) {
+		this.fail(UnterminatedParameter, this.position)
+		this.position = len(this.query)
+		return
+	}
+
+	end += this.position + 2
+	name = strings.TrimSpace(this.query[this.position + 2:end])
+
+	if(len(name) == 0) {
+		this.fail(EmptyParameterName, this.position)
+		this.position = end + 2
+		return
+	}
+
+	this.emit(slotToken, end + 2, name)
+}
+
+/*
+	isWordRune returns true if the given [character] would ordinarily be considered part of a word.
+*/
+func isWordRune(character rune) bool {
+	return character == '_' || unicode.IsLetter(character) || unicode.IsDigit(character) || unicode.IsMark(character)
+}
+
+/*
+	isWordByte returns true if the given byte can be part of an unquoted SQL word.
+	Bytes of multi-byte characters are treated as word bytes.
+*/
+func isWordByte(character byte) bool {
+
+	return character == '_' || character == '$' ||
+		(character >= 'a' && character <= 'z') ||
+		(character >= 'A' && character <= 'Z') ||
+		(character >= '0' && character <= '9') ||
+		character >= utf8.RuneSelf
+}
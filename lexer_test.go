@@ -0,0 +1,236 @@
+package namedParameterQuery
+
+import (
+	"testing"
+)
+
+/*
+	Represents a single test of the lexer, as seen through query parsing.
+	Given an [Input] query parsed using [Dialect], the parsed query must match [Expected]
+	and have [ExpectedParameters] positional parameters.
+*/
+type LexingTest struct {
+	Name string
+	Dialect Dialect
+	Input string
+	Expected string
+	ExpectedParameters int
+}
+
+func TestLexing(test *testing.T) {
+
+	var query *NamedParameterQuery
+
+	lexingTests := []LexingTest {
+		LexingTest {
+			Name: "LineComment",
+			Dialect: MySQLDialect,
+			Input: "SELECT * FROM table -- where col1 = :foo\nWHERE col2 = :bar",
+			Expected: "SELECT * FROM table -- where col1 = :foo\nWHERE col2 = ?",
+			ExpectedParameters: 1,
+		},
+		LexingTest {
+			Name: "LineCommentAtEnd",
+			Dialect: MySQLDialect,
+			Input: "SELECT * FROM table WHERE col1 = :bar -- :foo",
+			Expected: "SELECT * FROM table WHERE col1 = ? -- :foo",
+			ExpectedParameters: 1,
+		},
+		LexingTest {
+			Name: "HashComment",
+			Dialect: MySQLDialect,
+			Input: "SELECT * FROM table # it's :foo\nWHERE col2 = :bar",
+			Expected: "SELECT * FROM table # it's :foo\nWHERE col2 = ?",
+			ExpectedParameters: 1,
+		},
+		LexingTest {
+			Name: "HashIsNotACommentInPostgres",
+			Dialect: PostgresDialect,
+			Input: "SELECT col1 # :bar FROM table",
+			Expected: "SELECT col1 # $1 FROM table",
+			ExpectedParameters: 1,
+		},
+		LexingTest {
+			Name: "BlockComment",
+			Dialect: MySQLDialect,
+			Input: "SELECT * FROM table /* :foo */ WHERE col1 = :bar",
+			Expected: "SELECT * FROM table /* :foo */ WHERE col1 = ?",
+			ExpectedParameters: 1,
+		},
+		LexingTest {
+			Name: "NestedBlockComment",
+			Dialect: PostgresDialect,
+			Input: "SELECT * FROM table /* outer /* inner */ :foo */ WHERE col1 = :bar",
+			Expected: "SELECT * FROM table /* outer /* inner */ :foo */ WHERE col1 = $1",
+			ExpectedParameters: 1,
+		},
+		LexingTest {
+			Name: "UnnestedBlockComment",
+			Dialect: MySQLDialect,
+			Input: "SELECT * FROM table /* outer /* inner */ WHERE col1 = :bar",
+			Expected: "SELECT * FROM table /* outer /* inner */ WHERE col1 = ?",
+			ExpectedParameters: 1,
+		},
+		LexingTest {
+			Name: "DoubleQuotedIdentifier",
+			Dialect: PostgresDialect,
+			Input: "SELECT \"weird:column\" FROM table WHERE col1 = :bar",
+			Expected: "SELECT \"weird:column\" FROM table WHERE col1 = $1",
+			ExpectedParameters: 1,
+		},
+		LexingTest {
+			Name: "BacktickIdentifier",
+			Dialect: MySQLDialect,
+			Input: "SELECT `weird:column` FROM table WHERE col1 = :bar",
+			Expected: "SELECT `weird:column` FROM table WHERE col1 = ?",
+			ExpectedParameters: 1,
+		},
+		LexingTest {
+			Name: "BracketIdentifier",
+			Dialect: SQLServerDialect,
+			Input: "SELECT [weird:column] FROM table WHERE col1 = :bar",
+			Expected: "SELECT [weird:column] FROM table WHERE col1 = @p1",
+			ExpectedParameters: 1,
+		},
+		LexingTest {
+			Name: "DoubledQuoteEscape",
+			Dialect: PostgresDialect,
+			Input: "SELECT * FROM table WHERE col1 = 'it''s :foo' AND col2 = :bar",
+			Expected: "SELECT * FROM table WHERE col1 = 'it''s :foo' AND col2 = $1",
+			ExpectedParameters: 1,
+		},
+		LexingTest {
+			Name: "BackslashEscape",
+			Dialect: MySQLDialect,
+			Input: "SELECT * FROM table WHERE col1 = 'it\\'s :foo' AND col2 = :bar",
+			Expected: "SELECT * FROM table WHERE col1 = 'it\\'s :foo' AND col2 = ?",
+			ExpectedParameters: 1,
+		},
+		LexingTest {
+			Name: "BackslashIsLiteralInStandardStrings",
+			Dialect: PostgresDialect,
+			Input: "SELECT * FROM table WHERE col1 = 'C:\\' AND col2 = :bar",
+			Expected: "SELECT * FROM table WHERE col1 = 'C:\\' AND col2 = $1",
+			ExpectedParameters: 1,
+		},
+		LexingTest {
+			Name: "EscapeString",
+			Dialect: PostgresDialect,
+			Input: "SELECT * FROM table WHERE col1 = E'it\\'s :foo' AND col2 = :bar",
+			Expected: "SELECT * FROM table WHERE col1 = E'it\\'s :foo' AND col2 = $1",
+			ExpectedParameters: 1,
+		},
+		LexingTest {
+			Name: "DollarQuoted",
+			Dialect: PostgresDialect,
+			Input: "SELECT $$ :foo $$, $body$ it's :foo $body$ FROM table WHERE col1 = :bar",
+			Expected: "SELECT $$ :foo $$, $body$ it's :foo $body$ FROM table WHERE col1 = $1",
+			ExpectedParameters: 1,
+		},
+		LexingTest {
+			Name: "DollarInIdentifier",
+			Dialect: PostgresDialect,
+			Input: "SELECT foo$bar$ FROM table WHERE col1 = :bar",
+			Expected: "SELECT foo$bar$ FROM table WHERE col1 = $1",
+			ExpectedParameters: 1,
+		},
+		LexingTest {
+			Name: "Cast",
+			Dialect: PostgresDialect,
+			Input: "SELECT :id::bigint, col1::text FROM table WHERE col2 = :bar",
+			Expected: "SELECT $1::bigint, col1::text FROM table WHERE col2 = $2",
+			ExpectedParameters: 2,
+		},
+		LexingTest {
+			Name: "CastOfCast",
+			Dialect: PostgresDialect,
+			Input: "SELECT :id::text::bigint",
+			Expected: "SELECT $1::text::bigint",
+			ExpectedParameters: 1,
+		},
+		LexingTest {
+			Name: "Assignment",
+			Dialect: PostgresDialect,
+			Input: "BEGIN x := :value; y:=1; END",
+			Expected: "BEGIN x := $1; y:=1; END",
+			ExpectedParameters: 1,
+		},
+		LexingTest {
+			Name: "ArraySlice",
+			Dialect: PostgresDialect,
+			Input: "SELECT arr[1:2], arr[lo:hi], arr[:2], arr[2:][1:1] FROM table WHERE id = :id",
+			Expected: "SELECT arr[1:2], arr[lo:hi], arr[:2], arr[2:][1:1] FROM table WHERE id = $1",
+			ExpectedParameters: 1,
+		},
+		LexingTest {
+			Name: "ArrayConstructor",
+			Dialect: PostgresDialect,
+			Input: "SELECT ARRAY[:a, :b] FROM table WHERE id = ANY(ARRAY[:ids])",
+			Expected: "SELECT ARRAY[$1, $2] FROM table WHERE id = ANY(ARRAY[$3])",
+			ExpectedParameters: 3,
+		},
+		LexingTest {
+			Name: "NoArraySlicesInMySQL",
+			Dialect: MySQLDialect,
+			Input: "SELECT * FROM table WHERE col1 = 1:foo",
+			Expected: "SELECT * FROM table WHERE col1 = 1?",
+			ExpectedParameters: 1,
+		},
+		LexingTest {
+			Name: "UnterminatedQuote",
+			Dialect: MySQLDialect,
+			Input: "SELECT * FROM table WHERE col1 = :bar AND col2 = 'oops",
+			Expected: "SELECT * FROM table WHERE col1 = ? AND col2 = 'oops",
+			ExpectedParameters: 1,
+		},
+	}
+
+	for _, lexingTest := range lexingTests {
+
+		query = NewNamedParameterQueryWithDialect(lexingTest.Input, lexingTest.Dialect)
+
+		if(query.GetParsedQuery() != lexingTest.Expected) {
+			test.Log("Test '", lexingTest.Name, "': Expected query text did not match actual parsed output")
+			test.Log("Actual: ", query.GetParsedQuery())
+			test.Fail()
+		}
+
+		if(len(query.GetParsedParameters()) != lexingTest.ExpectedParameters) {
+			test.Log("Test '", lexingTest.Name, "': Expected parameters did not match actual parsed parameter count")
+			test.Fail()
+		}
+	}
+
+	test.Logf("Run %d lexing tests", len(lexingTests))
+}
+
+/*
+	Ensures that the text of every token, concatenated, reproduces the original query.
+*/
+func TestLexingRoundTrip(test *testing.T) {
+
+	var actual string
+	var tokens []token
+
+	inputs := []string {
+		"SELECT :foo, 'a:b', \"c:d\", `e`, [f] -- :g\n/* :h /* :i */ */ $$ :j $$ $k$ :l $k$ E'\\' :m'",
+		"SELECT 'unterminated",
+		"SELECT /* unterminated",
+		"SELECT $tag$ unterminated",
+	}
+
+	for _, input := range inputs {
+
+		actual = ""
+		tokens, _ = lexQuery(input, QueryOptions { Dialect: PostgresDialect }.withDefaults())
+
+		for _, token := range tokens {
+			actual += token.text
+		}
+
+		if(actual != input) {
+			test.Log("Lexed tokens did not reproduce the input query. Actual: ", actual)
+			test.Fail()
+		}
+	}
+}
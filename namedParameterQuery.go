@@ -64,227 +64,169 @@
 
 		connection, _ := sql.Open("mysql", "user:pass@tcp(localhost:3306)/db")
 		connection.QueryRow(query.GetParsedQuery(), (query.GetParsedParameters())...)
-*/
-package namedParameterQuery
-
-import (
-	"bytes"
-	"errors"
-	"reflect"
-	"unicode"
-	"unicode/utf8"
-)
 
-/*
-	NamedParameterQuery handles the translation of named parameters to positional parameters, for SQL statements.
-	It is not recommended to create zero-valued NamedParameterQuery objects by yourself;
-	instead use NewNamedParameterQuery
-*/
-type NamedParameterQuery struct {
+	A NamedParameterQuery holds the values of its parameters, so it should not be shared between goroutines.
+	If you want to parse a query once (e.g. at package init) and use it from many goroutines,
+	parse it as a QueryTemplate instead, and Bind it once per execution:
 
-	// A map of parameter names as keys, with value as a slice of positional indices which match
-	// that parameter.
-	positions map[string][]int
+		var findUser = MustParseQueryTemplate("SELECT * FROM users WHERE id = :id", QueryOptions{})
 
-	// Contains all positional parameters, in order, ready to be used in the positional query.
-	parameters []interface{}
+		func handler(connection *sql.DB, id int) {
 
-	// The query containing named parameters, as passed in by NewNamedParameterQuery
-	originalQuery string
+			binding := findUser.Bind()
+			binding.SetValue("id", id)
 
-	// The query containing positional parameters, as generated by setQuery
-	revisedQuery string
-}
+			connection.QueryRow(binding.GetParsedQuery(), (binding.GetParsedParameters())...)
+		}
+*/
+package namedParameterQuery
 
 /*
-	NewNamedParameterQuery creates a new named parameter query using the given [queryText] as a SQL query which
-	contains named parameters. Named parameters are identified by starting with a ":"
-	e.g., ":name" refers to the parameter "name", and ":foo" refers to the parameter "foo".
+	NamedParameterQuery handles the translation of named parameters to positional parameters, for SQL statements.
+	It is not recommended to create zero-valued NamedParameterQuery objects by yourself;
+	instead use NewNamedParameterQuery
 
-	Except for their names, named parameters follow all the same rules as positional parameters;
-	they cannot be inside quoted strings, and cannot inject statements into a query. They can only
-	be used to insert values.
+	A NamedParameterQuery is a QueryTemplate and a single Binding of it, bundled together for convenience.
+	Every method of Binding can be used on it directly.
+	Since it holds parameter values, it is not safe to use from more than one goroutine at a time.
 */
-func NewNamedParameterQuery(queryText string) (*NamedParameterQuery) {
-
-	var ret *NamedParameterQuery
-
-	// TODO: I don't like using a map for such a small amount of elements.
-	// If this becomes a bottleneck for anyone, the first thing to do would
-	// be to make a slice and search routine for parameter positions.
-	ret = new(NamedParameterQuery)
-	ret.positions = make(map[string][]int, 8)
-	ret.setQuery(queryText)
-
-	return ret
+type NamedParameterQuery struct {
+	Binding
 }
 
 /*
-	setQuery parses out all named parameters, stores their locations, and
-	builds a "revised" query which uses positional parameters.
+	QueryOptions determines how a query is parsed. The zero value of every field is a sensible default.
 */
-func (this *NamedParameterQuery) setQuery(queryText string) {
-
-	var revisedBuilder bytes.Buffer
-	var parameterBuilder bytes.Buffer
-	var position []int
-	var character rune
-	var parameterName string
-	var width int
-	var positionIndex int
+type QueryOptions struct {
 
-	this.originalQuery = queryText
-	positionIndex = 0
+	// The dialect in which positional parameters are written. Defaults to MySQLDialect.
+	Dialect Dialect
 
-	for i := 0; i < len(queryText); {
+	// Determines which characters may be used in parameter names. Defaults to DefaultIdentifiers.
+	Identifiers IdentifierPolicy
 
-		character, width = utf8.DecodeRuneInString(queryText[i:])
-		i += width
+	// Determines which syntaxes mark a named parameter. Defaults to ColonPrefix.
+	Prefixes ParameterPrefix
 
-		// if it's a colon, do not write to builder, but grab name
-		if(character == ':') {
+	// Determines what happens when a parameter is given an empty slice. Defaults to EmptySlicesFail.
+	EmptySlices EmptySlicePolicy
 
-			for ;; {
+	// Joins the name of a nested struct field to the names of its own fields, when binding from a struct.
+	// Defaults to ".", so that field City of field Address is bound to ":Address.City";
+	// since that name needs DottedIdentifiers to parse, a separator such as "_" may suit other identifier policies.
+	FieldSeparator string
 
-				character, width = utf8.DecodeRuneInString(queryText[i:])
-				i += width
+	// Determines which names refer to the same parameter. Defaults to ExactNames.
+	Names NameMapper
 
-				if unicode.IsLetter(character) || unicode.IsDigit(character) {
-					parameterBuilder.WriteString(string(character))
-				} else {
-					break
-				}
-			}
+	// If set, "/*[ ... ]*/" marks an optional fragment of the query, which is left out unless
+	// every parameter inside it has been given a value. See Binding.GetParsedQuery.
+	OptionalFragments bool
 
-			// add to positions
-			parameterName = parameterBuilder.String()
-			position = this.positions[parameterName]
-			this.positions[parameterName] = append(position, positionIndex)
-			positionIndex++
-
-			revisedBuilder.WriteString("?")
-			parameterBuilder.Reset()
-
-			if(width <= 0) {
-				break
-			}
-		}
-
-		// otherwise write.
-		revisedBuilder.WriteString(string(character))
-
-		// if it's a quote, continue writing to builder, but do not search for parameters.
-		if(character == '\'') {
-
-			for ;; {
-
-				character, width = utf8.DecodeRuneInString(queryText[i:])
-				i += width
-				revisedBuilder.WriteString(string(character))
-
-				if(character == '\'') {
-					break
-				}
-			}
-		}
-	}
-
-	this.revisedQuery = revisedBuilder.String()
-	this.parameters = make([]interface{}, positionIndex)
+	// If set, "{{name}}" marks a slot into which another query can be composed. See QueryTemplate.Compose.
+	Slots bool
 }
 
 /*
-	GetParsedQuery returns a version of the original query text
-	whose named parameters have been replaced by positional parameters.
+	withDefaults returns a copy of these options, with every unset field replaced by its default.
 */
-func (this *NamedParameterQuery) GetParsedQuery() (string) {
-	return this.revisedQuery
+func (this QueryOptions) withDefaults() QueryOptions {
+
+	if(this.Dialect == nil) {
+		this.Dialect = MySQLDialect
+	}
+	if(this.Identifiers == nil) {
+		this.Identifiers = DefaultIdentifiers
+	}
+	if(this.Prefixes == 0) {
+		this.Prefixes = ColonPrefix
+	}
+	if(len(this.FieldSeparator) == 0) {
+		this.FieldSeparator = "."
+	}
+	if(this.Names == nil) {
+		this.Names = ExactNames
+	}
+	return this
 }
 
 /*
-	GetParsedParameters returns an array of parameter objects that match the positional parameter list
-	from GetParsedQuery
+	NewNamedParameterQuery creates a new named parameter query using the given [queryText] as a SQL query which
+	contains named parameters. Named parameters are identified by starting with a ":"
+	e.g., ":name" refers to the parameter "name", and ":foo" refers to the parameter "foo".
+
+	Except for their names, named parameters follow all the same rules as positional parameters;
+	they cannot be inside quoted strings, quoted identifiers or comments, and cannot inject statements into a query.
+	They can only be used to insert values.
+
+	The parsed query uses "?" for every positional parameter, which suits MySQL and SQLite drivers.
+	Use NewNamedParameterQueryWithDialect for other databases.
+
+	Parsed queries are kept in DefaultTemplateCache, so creating the same query again skips parsing entirely.
 */
-func (this *NamedParameterQuery) GetParsedParameters() ([]interface{}) {
-	return this.parameters
+func NewNamedParameterQuery(queryText string) (*NamedParameterQuery) {
+	return NewNamedParameterQueryWithDialect(queryText, MySQLDialect)
 }
 
 /*
-	SetValue sets the value of the given [parameterName] to the given [parameterValue].
-	If the parsed query does not have a placeholder for the given [parameterName],
-	this method does nothing.
+	NewNamedParameterQueryWithDialect creates a new named parameter query, exactly as NewNamedParameterQuery does,
+	except that positional parameters in the parsed query are written in the style of the given [dialect].
+	e.g., using PostgresDialect, "WHERE col1 = :foo AND col2 = :bar" is parsed as "WHERE col1 = $1 AND col2 = $2".
+
+	If the [dialect] reuses placeholders, a named parameter which appears more than once
+	is written with the same placeholder every time, and appears only once in GetParsedParameters.
 */
-func (this *NamedParameterQuery) SetValue(parameterName string, parameterValue interface{}) {
+func NewNamedParameterQueryWithDialect(queryText string, dialect Dialect) (*NamedParameterQuery) {
 
-	for _, position := range this.positions[parameterName] {
-		this.parameters[position] = parameterValue
-	}
+	ret, _ := parseNamedParameterQuery(queryText, QueryOptions { Dialect: dialect })
+	return ret
 }
 
 /*
-	SetValuesFromMap uses every key/value pair in the given [parameters] as a parameter replacement
-	for this query. This is equivalent to calling SetValue for every key/value pair
-	in the given [parameters] map.
-	If there are any keys/values present in the map that aren't part of the query,
-	they are ignored.
-*/
-func (this *NamedParameterQuery) SetValuesFromMap(parameters map[string]interface{}) {
+	ParseNamedParameterQuery creates a new named parameter query in the same way as NewNamedParameterQuery,
+	except that a malformed [queryText] is reported as a *ParseError, rather than parsed as well as possible.
+	e.g., an unterminated string, an unterminated comment, or a ":" which is not followed by a parameter name.
 
-	for name, value := range parameters {
-		this.SetValue(name, value)
-	}
+	This is best used for queries which are defined at startup, so that mistakes are caught as early as possible.
+*/
+func ParseNamedParameterQuery(queryText string) (*NamedParameterQuery, error) {
+	return ParseNamedParameterQueryWithOptions(queryText, QueryOptions{})
 }
 
 /*
-	SetValuesFromStruct uses reflection to find every public field of the given struct [parameters]
-	and set their key/value as named parameters in this query.
-	If the given [parameters] is not a struct, this will return an error.
+	ParseNamedParameterQueryWithOptions creates a new named parameter query in the same way as ParseNamedParameterQuery,
+	but parses the [queryText] according to the given [options]. e.g., to allow "user.id"-style parameter names:
 
-	If you do not wish for a field in the struct to be added by its literal name,
-	The struct may optionally specify the sqlParameterName as a tag on the field.
-	e.g., a struct field may say something like:
-
-		type Test struct {
-			Foo string `sqlParameterName:"foobar"`
-		}
+		query, err := ParseNamedParameterQueryWithOptions(queryText, QueryOptions {
+			Dialect: PostgresDialect,
+			Identifiers: DottedIdentifiers,
+		})
 */
-func (this *NamedParameterQuery) SetValuesFromStruct(parameters interface{}) (error) {
+func ParseNamedParameterQueryWithOptions(queryText string, options QueryOptions) (*NamedParameterQuery, error) {
 
-	var fieldValues reflect.Value
-	var fieldValue reflect.Value
-	var parameterType reflect.Type
-	var parameterField reflect.StructField
-	var queryTag string
-	var visibilityCharacter rune
-
-	fieldValues = reflect.ValueOf(parameters)
-
-	if(fieldValues.Kind() != reflect.Struct) {
-		return errors.New("Unable to add query values from parameter: parameter is not a struct")
+	ret, err := parseNamedParameterQuery(queryText, options)
+	if(err != nil) {
+		return nil, err
 	}
+	return ret, nil
+}
 
-	parameterType = fieldValues.Type()
-
-	for i := 0; i < fieldValues.NumField(); i++ {
-
-		fieldValue = fieldValues.Field(i)
-		parameterField = parameterType.Field(i)
-
-		// public field?
-		visibilityCharacter, _ = utf8.DecodeRuneInString(parameterField.Name[0:])
+/*
+	parseNamedParameterQuery creates a new named parameter query using the given [options],
+	reusing an already-parsed template from DefaultTemplateCache where possible.
+	The query is always returned, even if the query text was malformed.
+*/
+func parseNamedParameterQuery(queryText string, options QueryOptions) (*NamedParameterQuery, error) {
 
-		if(fieldValue.CanSet() || unicode.IsUpper(visibilityCharacter)) {
+	var ret *NamedParameterQuery
+	var template *QueryTemplate
+	var err error
 
-			// check to see if this has a tag indicating a different query name
-			queryTag = parameterField.Tag.Get("sqlParameterName")
+	template, err = DefaultTemplateCache.parse(queryText, options)
 
-			// otherwise just add the struct's name.
-			if(len(queryTag) <= 0) {
-				queryTag = parameterField.Name
-			}
+	ret = new(NamedParameterQuery)
+	ret.Binding.setTemplate(template)
 
-			this.SetValue(queryTag, fieldValue.Interface())
-		}
-	}
-	return nil
+	return ret, err
 }
+
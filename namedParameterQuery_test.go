@@ -2,6 +2,7 @@ package namedParameterQuery
 
 import (
 	"testing"
+	"time"
 )
 
 /*
@@ -340,6 +341,99 @@ func TestStructParameters(test *testing.T) {
 	})
 }
 
+type NestedParameterAudit struct {
+	CreatedBy string
+}
+
+type NestedParameterAddress struct {
+	City string `sqlParameterName:"city"`
+}
+
+type NestedParameterTest struct {
+	NestedParameterAudit
+	Name string
+	Address NestedParameterAddress `sqlParameterName:"address"`
+	Billing *NestedParameterAddress
+	Shipping *NestedParameterAddress
+	Created time.Time
+}
+
+func TestNestedStructParameters(test *testing.T) {
+
+	var nested NestedParameterTest
+	var query *NamedParameterQuery
+
+	nested.CreatedBy = "admin"
+	nested.Name = "alice"
+	nested.Address.City = "Paris"
+	nested.Billing = &NestedParameterAddress { City: "Rome" }
+	nested.Created = time.Unix(0, 0)
+
+	query, _ = ParseNamedParameterQueryWithOptions(
+		"INSERT INTO table VALUES (:CreatedBy, :Name, :address.city, :Billing.city, :Shipping.city, :Created)",
+		QueryOptions { Identifiers: DottedIdentifiers },
+	)
+	query.SetValuesFromStruct(nested)
+
+	verifyStructParameters("NestedStructReplacement", test, query, []interface{} {
+		"admin",
+		"alice",
+		"Paris",
+		"Rome",
+		nil,
+		nested.Created,
+	})
+
+	query, _ = ParseNamedParameterQueryWithOptions(
+		"SELECT * FROM table WHERE col1 = :address_city",
+		QueryOptions { FieldSeparator: "_" },
+	)
+	query.SetValuesFromStruct(nested)
+
+	verifyStructParameters("NestedStructSeparator", test, query, []interface{} {
+		"Paris",
+	})
+}
+
+type TagOptionsTest struct {
+	Status string `sqlParameterName:"status,omitempty"`
+	Limit int `db:"limit,default=50"`
+	Kind string `sqlParameterName:",default=any"`
+	Secret string `sqlParameterName:"-"`
+	Owner string `db:"owner" sqlParameterName:"owner_id"`
+}
+
+func TestStructTagOptions(test *testing.T) {
+
+	var query *NamedParameterQuery
+
+	query = NewNamedParameterQuery("SELECT * FROM table WHERE col1 = :status AND col2 = :limit AND col3 = :Kind AND col4 = :Secret AND col5 = :owner_id")
+	query.SetValuesFromStruct(TagOptionsTest { Secret: "hunter2", Owner: "alice" })
+
+	verifyStructParameters("ZeroValueTagOptions", test, query, []interface{} {
+		nil,
+		50,
+		"any",
+		nil,
+		"alice",
+	})
+
+	err := query.Validate()
+	if(err == nil || err.Error() != "Unable to bind query: missing parameters: Secret, status") {
+		test.Log("Unexpected validation error: ", err)
+		test.Fail()
+	}
+
+	query = NewNamedParameterQuery("SELECT * FROM table WHERE col1 = :status AND col2 = :limit AND col3 = :Kind")
+	query.SetValuesFromStruct(TagOptionsTest { Status: "open", Limit: 10, Kind: "bug" })
+
+	verifyStructParameters("SetValueTagOptions", test, query, []interface{} {
+		"open",
+		10,
+		"bug",
+	})
+}
+
 func verifyStructParameters(testName string, test *testing.T, query *NamedParameterQuery, expectedParameters []interface{}) {
 
 	var actualParameters []interface{}
@@ -363,3 +457,111 @@ func verifyStructParameters(testName string, test *testing.T, query *NamedParame
 
 	test.Logf("Run %d struct reflection parameter tests", actualParameterLength)
 }
+
+/*
+	Represents a single test of parameter validation.
+	Given a [Query] with [Strict] set, binding [Parameters] must leave exactly
+	[ExpectedMissing] and [ExpectedUnknown] reported by Validate.
+*/
+type ValidationTest struct {
+	Name string
+	Query string
+	Strict bool
+	Parameters map[string]interface{}
+	ExpectedMissing []string
+	ExpectedUnknown []string
+}
+
+func TestValidation(test *testing.T) {
+
+	var query *NamedParameterQuery
+	var bindingError *BindingError
+	var ok bool
+
+	validationTests := []ValidationTest {
+		ValidationTest {
+			Name: "AllBound",
+			Query: "SELECT * FROM table WHERE col1 = :foo AND col2 = :bar AND col3 = :foo",
+			Parameters: map[string]interface{} { "foo": 1, "bar": nil },
+		},
+		ValidationTest {
+			Name: "Missing",
+			Query: "SELECT * FROM table WHERE col1 = :foo AND col2 = :bar AND col3 = :baz",
+			Parameters: map[string]interface{} { "foo": 1 },
+			ExpectedMissing: []string { "bar", "baz" },
+		},
+		ValidationTest {
+			Name: "UnknownIgnoredWhenLenient",
+			Query: "SELECT * FROM table WHERE col1 = :foo",
+			Parameters: map[string]interface{} { "foo": 1, "fooo": 2 },
+		},
+		ValidationTest {
+			Name: "UnknownWhenStrict",
+			Query: "SELECT * FROM table WHERE col1 = :foo AND col2 = :bar",
+			Strict: true,
+			Parameters: map[string]interface{} { "foo": 1, "fooo": 2, "baar": 3 },
+			ExpectedMissing: []string { "bar" },
+			ExpectedUnknown: []string { "baar", "fooo" },
+		},
+	}
+
+	for _, validationTest := range validationTests {
+
+		query = NewNamedParameterQuery(validationTest.Query)
+		query.SetStrict(validationTest.Strict)
+		query.SetValuesFromMap(validationTest.Parameters)
+
+		err := query.Validate()
+		_, argsErr := query.Args()
+
+		if(len(validationTest.ExpectedMissing) == 0 && len(validationTest.ExpectedUnknown) == 0) {
+
+			if(err != nil || argsErr != nil) {
+				test.Log("Test '", validationTest.Name, "': Expected no validation error, got: ", err)
+				test.Fail()
+			}
+			continue
+		}
+
+		bindingError, ok = err.(*BindingError)
+		if(!ok || argsErr == nil) {
+			test.Log("Test '", validationTest.Name, "': Expected a BindingError, got: ", err)
+			test.Fail()
+			continue
+		}
+
+		if(!equalNames(bindingError.Missing, validationTest.ExpectedMissing) || !equalNames(bindingError.Unknown, validationTest.ExpectedUnknown)) {
+			test.Log("Test '", validationTest.Name, "': Unexpected validation error: ", err)
+			test.Fail()
+		}
+	}
+}
+
+func TestStrictStructParameters(test *testing.T) {
+
+	var singleParam SingleParameterTest
+
+	query := NewNamedParameterQuery("SELECT * FROM table WHERE col1 = :Foo AND col2 = :Qux")
+	query.SetStrict(true)
+	query.SetValuesFromStruct(singleParam)
+
+	err := query.Validate()
+	if(err == nil || err.Error() != "Unable to bind query: missing parameters: Qux; unknown parameters: Bar, Baz") {
+		test.Log("Unexpected validation error: ", err)
+		test.Fail()
+	}
+}
+
+func equalNames(actual []string, expected []string) bool {
+
+	if(len(actual) != len(expected)) {
+		return false
+	}
+
+	for index, name := range actual {
+		if(name != expected[index]) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,83 @@
+package namedParameterQuery
+
+import (
+	"reflect"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+/*
+	NameMapper decides which names refer to the same parameter.
+	Every parameter name in a query, and every name given a value (by SetValue, SetValuesFromMap, SetValuesFromStruct, etc),
+	is passed through the query's NameMapper; two names refer to the same parameter if they map to the same string.
+	Missing parameters are reported by Validate under their mapped names.
+
+	The mapper is given to ParseNamedParameterQueryWithOptions through QueryOptions.
+	Any func can be used, but the built-in mappers should cover most cases.
+*/
+type NameMapper func(name string) string
+
+/*
+	ExactNames leaves names as they are, so that ":foo" and ":FOO" are different parameters. This is the default.
+*/
+func ExactNames(name string) string {
+	return name
+}
+
+/*
+	CaseInsensitiveNames lowercases names, so that ":foo", ":Foo", and ":FOO" are all the same parameter.
+*/
+func CaseInsensitiveNames(name string) string {
+	return strings.ToLower(name)
+}
+
+/*
+	SnakeCaseNames converts CamelCase names to snake_case, so that a struct field "UserID"
+	(or the names "userId" and "user_id") sets the parameter ":user_id".
+	A run of capitals is treated as one word, so "HTTPStatus" becomes "http_status".
+*/
+func SnakeCaseNames(name string) string {
+
+	var builder strings.Builder
+	var previous rune
+	var next rune
+
+	builder.Grow(len(name) + 4)
+
+	for index, character := range name {
+
+		if(unicode.IsUpper(character)) {
+
+			next, _ = utf8.DecodeRuneInString(name[index + utf8.RuneLen(character):])
+
+			// a capital starts a new word after a lowercase letter or digit, or when it begins a word after a run of capitals.
+			if(index > 0 && (unicode.IsLower(previous) || unicode.IsDigit(previous) || (unicode.IsUpper(previous) && unicode.IsLower(next)))) {
+				builder.WriteByte('_')
+			}
+			builder.WriteRune(unicode.ToLower(character))
+
+		} else {
+			builder.WriteRune(character)
+		}
+
+		previous = character
+	}
+	return builder.String()
+}
+
+/*
+	isExactNames returns true if the given [mapper] is ExactNames, which leaves every name as it is.
+*/
+func isExactNames(mapper NameMapper) (bool) {
+	return reflect.ValueOf(mapper).Pointer() == reflect.ValueOf(ExactNames).Pointer()
+}
+
+/*
+	builtinNameMappers identifies the built-in mappers by their code pointer, as builtinIdentifierPolicies does.
+*/
+var builtinNameMappers = map[uintptr]bool {
+	reflect.ValueOf(ExactNames).Pointer(): true,
+	reflect.ValueOf(CaseInsensitiveNames).Pointer(): true,
+	reflect.ValueOf(SnakeCaseNames).Pointer(): true,
+}
@@ -0,0 +1,75 @@
+package namedParameterQuery
+
+import (
+	"testing"
+)
+
+func TestSnakeCaseNames(test *testing.T) {
+
+	names := map[string]string {
+		"UserID": "user_id",
+		"userId": "user_id",
+		"user_id": "user_id",
+		"ID": "id",
+		"HTTPStatus": "http_status",
+		"Address2Line": "address2_line",
+		"Address.City": "address.city",
+	}
+
+	for input, expected := range names {
+
+		actual := SnakeCaseNames(input)
+		if(actual != expected) {
+			test.Log("Expected '", input, "' to map to '", expected, "', got: ", actual)
+			test.Fail()
+		}
+	}
+}
+
+type nameMappingTest struct {
+	UserID int
+	DisplayName string
+}
+
+func TestNameMapping(test *testing.T) {
+
+	var query *NamedParameterQuery
+
+	query, _ = ParseNamedParameterQueryWithOptions("SELECT * FROM table WHERE col1 = :foo AND col2 = :FOO AND col3 = :Bar",
+		QueryOptions { Names: CaseInsensitiveNames })
+
+	query.SetValuesFromMap(map[string]interface{} { "Foo": 1, "bar": 2 })
+	verifyStructParameters("CaseInsensitiveNames", test, query, []interface{} { 1, 1, 2 })
+
+	query, _ = ParseNamedParameterQueryWithOptions("UPDATE users SET display_name = :display_name WHERE id = :user_id",
+		QueryOptions { Names: SnakeCaseNames })
+
+	query.SetValuesFromStruct(nameMappingTest { UserID: 5, DisplayName: "alice" })
+	verifyStructParameters("SnakeCaseNames", test, query, []interface{} { "alice", 5 })
+
+	query, _ = ParseNamedParameterQueryWithOptions("SELECT * FROM table WHERE col1 = :foo",
+		QueryOptions { Names: func(name string) string { return "x" } })
+
+	query.SetStrict(true)
+	query.SetValue("anything", 3)
+	verifyStructParameters("CustomNames", test, query, []interface{} { 3 })
+
+	if(query.Validate() != nil) {
+		test.Log("Expected every name to match under a custom mapper: ", query.Validate())
+		test.Fail()
+	}
+}
+
+/*
+	Ensures that a shallower field shadows a deeper one whose name only maps to the same parameter.
+*/
+func TestMappedNameShadowing(test *testing.T) {
+
+	query, _ := ParseNamedParameterQueryWithOptions("SELECT * FROM table WHERE name = :name", QueryOptions { Names: CaseInsensitiveNames })
+
+	query.SetValuesFromStruct(reflectionTestOuter {
+		reflectionTestInner: reflectionTestInner { Name: "inner" },
+		Name: "outer",
+	})
+	verifyStructParameters("MappedNameShadowing", test, query, []interface{} { "outer" })
+}
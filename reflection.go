@@ -0,0 +1,388 @@
+package namedParameterQuery
+
+import (
+	"database/sql/driver"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+
+	// The tags which name a struct field when binding it as a parameter.
+	parameterTags = "sqlParameterName,db"
+
+	// The tags which name a struct field when scanning a column into it.
+	columnTags = "sqlColumnName,sqlParameterName,db"
+)
+
+var (
+	valuerType = reflect.TypeOf((*driver.Valuer)(nil)).Elem()
+	timeType = reflect.TypeOf(time.Time{})
+)
+
+/*
+	structField describes a single field of a struct which can be bound to a parameter or scanned from a column,
+	including fields promoted from embedded structs.
+*/
+type structField struct {
+
+	// The name used to refer to this field from a query.
+	name string
+
+	// The path of field indices which leads to this field from the outer struct, as used by reflect's FieldByIndex.
+	index []int
+
+	// Whether this field was named by its tag, rather than by its Go name.
+	tagged bool
+
+	// Whether this field holds a struct whose own fields should be bound individually. See isNestedStruct.
+	nested bool
+
+	// Whether a zero value in this field should be left unbound, rather than bound.
+	omitEmpty bool
+
+	// If not nil, the value to bind in place of a zero value in this field.
+	defaultValue interface{}
+}
+
+/*
+	structFieldsKey identifies a struct type, as seen through a particular set of tags.
+*/
+type structFieldsKey struct {
+	structType reflect.Type
+	tags string
+}
+
+/*
+	structFieldCache holds the result of structFields for every struct type (and set of tags) seen so far,
+	so that binding or scanning the same type again does not need to walk its fields.
+	The number of struct types in a program is fixed, so this never needs evicting.
+*/
+var structFieldCache sync.Map
+
+/*
+	structFields returns every accessible field of the given [structType], flattening embedded structs
+	so that their fields are named as if they belonged to the outer struct, as Go's field promotion does.
+
+	Each field is named by the first of the given comma-separated [tags] which it has, or else by its Go name,
+	and fields tagged "-" are left out (see fieldTag).
+	An embedded struct which is given a name by its tag is treated as an ordinary field, rather than flattened.
+	If more than one field ends up with the same name, the least deeply embedded wins; see resolveShadowing.
+
+	The result is cached per type, and must not be modified.
+*/
+func structFields(structType reflect.Type, tags string) ([]structField) {
+
+	var key structFieldsKey
+
+	key = structFieldsKey { structType: structType, tags: tags }
+
+	cached, found := structFieldCache.Load(key)
+	if(found) {
+		return cached.([]structField)
+	}
+
+	cached, _ = structFieldCache.LoadOrStore(key, walkStructFields(structType, strings.Split(tags, ",")))
+	return cached.([]structField)
+}
+
+/*
+	walkStructFields does the work of structFields, without the cache.
+*/
+func walkStructFields(structType reflect.Type, tags []string) ([]structField) {
+
+	var ret []structField
+
+	ret = appendStructFields(ret, structType, nil, tags)
+
+	sort.SliceStable(ret, func(left, right int) bool {
+		return len(ret[left].index) < len(ret[right].index)
+	})
+	return resolveShadowing(ret, ExactNames)
+}
+
+/*
+	resolveShadowing returns the given [fields], which must be sorted from shallowest to deepest, without those
+	which are shadowed by another field whose name has the same [key]. As with Go's field promotion,
+	a field is shadowed by any shallower field of the same name, and fields of the same name at the same depth
+	are ambiguous, so none of them are used; unless, as in encoding/json, exactly one of them is named by its tag.
+*/
+func resolveShadowing(fields []structField, key NameMapper) ([]structField) {
+
+	var candidates map[string][]int
+	var keep []bool
+	var ret []structField
+	var name string
+	var tagged int
+	var taggedCount int
+
+	// the indices of the shallowest fields with each name.
+	candidates = make(map[string][]int, len(fields))
+
+	for index, field := range fields {
+
+		name = key(field.name)
+		existing := candidates[name]
+
+		if(len(existing) > 0 && len(fields[existing[0]].index) < len(field.index)) {
+			continue
+		}
+		candidates[name] = append(existing, index)
+	}
+
+	keep = make([]bool, len(fields))
+
+	for _, indices := range candidates {
+
+		if(len(indices) == 1) {
+			keep[indices[0]] = true
+			continue
+		}
+
+		taggedCount = 0
+		for _, index := range indices {
+			if(fields[index].tagged) {
+				tagged = index
+				taggedCount++
+			}
+		}
+
+		if(taggedCount == 1) {
+			keep[tagged] = true
+		}
+	}
+
+	ret = make([]structField, 0, len(fields))
+	for index, field := range fields {
+		if(keep[index]) {
+			ret = append(ret, field)
+		}
+	}
+	return ret
+}
+
+func appendStructFields(fields []structField, structType reflect.Type, parentIndex []int, tags []string) ([]structField) {
+
+	var field reflect.StructField
+	var fieldType reflect.Type
+	var index []int
+	var tag fieldTag
+
+	for i := 0; i < structType.NumField(); i++ {
+
+		field = structType.Field(i)
+		index = append(append([]int(nil), parentIndex...), i)
+		tag = parseFieldTag(field, tags)
+
+		if(tag.skip) {
+			continue
+		}
+
+		fieldType = field.Type
+		if(fieldType.Kind() == reflect.Ptr) {
+			fieldType = fieldType.Elem()
+		}
+
+		// unnamed embedded structs are flattened. Embedded pointers must be exported, so they can be allocated.
+		if(field.Anonymous && len(tag.name) == 0 && fieldType.Kind() == reflect.Struct &&
+			(field.Type.Kind() != reflect.Ptr || len(field.PkgPath) == 0)) {
+
+			fields = appendStructFields(fields, fieldType, index, tags)
+			continue
+		}
+
+		// public field?
+		if(len(field.PkgPath) > 0) {
+			continue
+		}
+
+		tagged := len(tag.name) > 0
+		if(!tagged) {
+			tag.name = field.Name
+		}
+
+		fields = append(fields, structField {
+			name: tag.name,
+			index: index,
+			tagged: tagged,
+			nested: isNestedStruct(field.Type),
+			omitEmpty: tag.omitEmpty,
+			defaultValue: tag.defaultValue(field.Type),
+		})
+	}
+	return fields
+}
+
+/*
+	fieldTag holds the options given by a field's tag, which is written as a name followed by comma-separated options:
+
+		`sqlParameterName:"name,omitempty,default=value"`
+
+	Either part may be left out; e.g. `sqlParameterName:",omitempty"` keeps the field's Go name.
+	A tag of "-" excludes the field altogether.
+*/
+type fieldTag struct {
+	name string
+	skip bool
+	omitEmpty bool
+	hasDefault bool
+	defaultText string
+}
+
+/*
+	parseFieldTag parses the first of the given [tags] which the given [field] has.
+	If it has none of them, the result is empty.
+*/
+func parseFieldTag(field reflect.StructField, tags []string) (fieldTag) {
+
+	var ret fieldTag
+	var value string
+	var options []string
+
+	for _, tag := range tags {
+
+		value = field.Tag.Get(tag)
+		if(len(value) > 0) {
+			break
+		}
+	}
+
+	if(value == "-") {
+		ret.skip = true
+		return ret
+	}
+
+	options = strings.Split(value, ",")
+	ret.name = options[0]
+
+	for _, option := range options[1:] {
+
+		switch {
+		case option == "omitempty":
+			ret.omitEmpty = true
+
+		case strings.HasPrefix(option, "default="):
+			ret.hasDefault = true
+			ret.defaultText = strings.TrimPrefix(option, "default=")
+		}
+	}
+	return ret
+}
+
+/*
+	defaultValue returns this tag's default, converted to the given [fieldType] where it's a plain boolean, number or string.
+	Defaults which can't be converted are given as strings. If this tag has no default, nil is returned.
+*/
+func (this fieldTag) defaultValue(fieldType reflect.Type) (interface{}) {
+
+	var parsed interface{}
+	var err error
+
+	if(!this.hasDefault) {
+		return nil
+	}
+
+	if(fieldType.Kind() == reflect.Ptr) {
+		fieldType = fieldType.Elem()
+	}
+
+	switch fieldType.Kind() {
+	case reflect.Bool:
+		parsed, err = strconv.ParseBool(this.defaultText)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		parsed, err = strconv.ParseInt(this.defaultText, 10, 64)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		parsed, err = strconv.ParseUint(this.defaultText, 10, 64)
+	case reflect.Float32, reflect.Float64:
+		parsed, err = strconv.ParseFloat(this.defaultText, 64)
+	case reflect.String:
+		parsed = this.defaultText
+	default:
+		return this.defaultText
+	}
+
+	if(err != nil) {
+		return this.defaultText
+	}
+	return reflect.ValueOf(parsed).Convert(fieldType).Interface()
+}
+
+/*
+	fieldByIndex returns the field of [value] at the given [index] path, as reflect's FieldByIndex does,
+	except that nil embedded struct pointers are given a new, zero-valued struct along the way.
+*/
+func fieldByIndex(value reflect.Value, index []int) (reflect.Value) {
+
+	for depth, fieldIndex := range index {
+
+		if(depth > 0 && value.Kind() == reflect.Ptr) {
+
+			if(value.IsNil()) {
+				value.Set(reflect.New(value.Type().Elem()))
+			}
+			value = value.Elem()
+		}
+		value = value.Field(fieldIndex)
+	}
+	return value
+}
+
+/*
+	readFieldByIndex returns the field of [value] at the given [index] path, as reflect's FieldByIndex does,
+	or false if the path passes through a nil embedded struct pointer.
+*/
+func readFieldByIndex(value reflect.Value, index []int) (reflect.Value, bool) {
+
+	for depth, fieldIndex := range index {
+
+		if(depth > 0 && value.Kind() == reflect.Ptr) {
+
+			if(value.IsNil()) {
+				return value, false
+			}
+			value = value.Elem()
+		}
+		value = value.Field(fieldIndex)
+	}
+	return value, true
+}
+
+/*
+	isNestedStruct returns true if the given [fieldType] is a struct (or pointer to one) whose fields should be
+	bound individually, rather than a value such as time.Time or sql.NullString which should be bound as a whole.
+*/
+func isNestedStruct(fieldType reflect.Type) (bool) {
+
+	if(fieldType.Kind() == reflect.Ptr) {
+		fieldType = fieldType.Elem()
+	}
+
+	if(fieldType.Kind() != reflect.Struct || fieldType == timeType) {
+		return false
+	}
+	return !fieldType.Implements(valuerType) && !reflect.PtrTo(fieldType).Implements(valuerType)
+}
+
+/*
+	indirect follows the given [value] through any pointers and interfaces to whatever they hold,
+	or returns false if it runs into a nil.
+*/
+func indirect(value reflect.Value) (reflect.Value, bool) {
+
+	if(!value.IsValid()) {
+		return value, false
+	}
+
+	for value.Kind() == reflect.Ptr || value.Kind() == reflect.Interface {
+
+		if(value.IsNil()) {
+			return value, false
+		}
+		value = value.Elem()
+	}
+	return value, true
+}
@@ -0,0 +1,104 @@
+package namedParameterQuery
+
+import (
+	"reflect"
+	"testing"
+)
+
+type reflectionTestInner struct {
+	Name string
+	Depth int
+}
+
+type reflectionTestOuter struct {
+	reflectionTestInner
+	Name string `sqlParameterName:"name"`
+	Other reflectionTestInner
+}
+
+type reflectionTestFirst struct {
+	ID int
+	First string
+}
+
+type reflectionTestSecond struct {
+	ID int
+	Second string
+}
+
+type reflectionTestAmbiguous struct {
+	reflectionTestFirst
+	reflectionTestSecond
+}
+
+type reflectionTestTagged struct {
+	reflectionTestFirst
+	reflectionTestSecond `sqlParameterName:"-"`
+	Key int `sqlParameterName:"First"`
+	First int
+}
+
+func TestStructFields(test *testing.T) {
+
+	var names []string
+
+	structType := reflect.TypeOf(reflectionTestOuter{})
+	fields := structFields(structType, parameterTags)
+
+	for _, field := range fields {
+		names = append(names, field.name)
+	}
+
+	if(!equalNames(names, []string { "name", "Other", "Name", "Depth" })) {
+		test.Log("Unexpected struct fields: ", names)
+		test.Fail()
+	}
+
+	if(!fields[1].nested || fields[0].nested) {
+		test.Log("Expected only the nested struct field to be marked as nested")
+		test.Fail()
+	}
+
+	// every later call should come from the cache.
+	if(&structFields(structType, parameterTags)[0] != &fields[0]) {
+		test.Log("Expected struct fields to be cached")
+		test.Fail()
+	}
+
+	if(structFields(structType, columnTags)[0].name != "name") {
+		test.Log("Expected the column tags to find the parameter tag")
+		test.Fail()
+	}
+}
+
+/*
+	Ensures that, as in Go, a name shared by fields at the same depth is ambiguous, and so bound to neither field.
+*/
+func TestAmbiguousStructFields(test *testing.T) {
+
+	var names []string
+
+	for _, field := range structFields(reflect.TypeOf(reflectionTestAmbiguous{}), parameterTags) {
+		names = append(names, field.name)
+	}
+
+	if(!equalNames(names, []string { "First", "Second" })) {
+		test.Log("Expected ambiguous fields to be left out, got: ", names)
+		test.Fail()
+	}
+
+	query := NewNamedParameterQuery("SELECT * FROM table WHERE id = :ID")
+	query.SetValuesFromStruct(reflectionTestAmbiguous { reflectionTestFirst { ID: 1 }, reflectionTestSecond { ID: 2 } })
+
+	if(query.IsBound("ID")) {
+		test.Log("Expected an ambiguous field to be left unbound")
+		test.Fail()
+	}
+
+	// a tagged field beats an untagged field at the same depth, as in encoding/json.
+	fields := structFields(reflect.TypeOf(reflectionTestTagged{}), parameterTags)
+	if(len(fields) != 2 || fields[0].name != "First" || fields[0].index[0] != 2 || fields[1].name != "ID") {
+		test.Log("Expected the tagged field to be kept, got: ", fields)
+		test.Fail()
+	}
+}
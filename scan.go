@@ -0,0 +1,199 @@
+package namedParameterQuery
+
+import (
+	"database/sql"
+	"errors"
+	"reflect"
+	"strings"
+)
+
+/*
+	UnmatchedColumnPolicy determines what a RowScanner does with a column which matches no field of the struct.
+*/
+type UnmatchedColumnPolicy int
+
+const (
+
+	// A column with no matching field is an error. This is the default.
+	UnmatchedColumnsFail UnmatchedColumnPolicy = iota
+
+	// A column with no matching field is read, then thrown away.
+	UnmatchedColumnsIgnore
+)
+
+/*
+	RowScanner scans the rows of a query result into structs, matching columns to fields by name.
+
+	Each field is matched to the column named by its sqlColumnName tag, or else its sqlParameterName tag,
+	or else its db tag, or else its Go name. Fields tagged "-" are never matched. Names are first compared exactly, then without regard to case,
+	so an untagged "Name" field matches a "name" column.
+	Fields of embedded structs are matched as though they belonged to the outer struct.
+
+	Fields are scanned with *sql.Rows' Scan, so pointer fields may be used for nullable columns,
+	and any field type which implements sql.Scanner works as expected.
+
+	The zero value scans with UnmatchedColumnsFail.
+*/
+type RowScanner struct {
+	UnmatchedColumns UnmatchedColumnPolicy
+}
+
+/*
+	ScanRow scans the current row of [rows] into the struct pointed to by [dest], using a zero-valued RowScanner.
+*/
+func ScanRow(rows *sql.Rows, dest interface{}) (error) {
+	return RowScanner{}.ScanRow(rows, dest)
+}
+
+/*
+	ScanAll scans every remaining row of [rows] into the slice pointed to by [dest], using a zero-valued RowScanner.
+*/
+func ScanAll(rows *sql.Rows, dest interface{}) (error) {
+	return RowScanner{}.ScanAll(rows, dest)
+}
+
+/*
+	ScanRow scans the current row of [rows] (i.e. after a call to Next) into the struct pointed to by [dest].
+*/
+func (this RowScanner) ScanRow(rows *sql.Rows, dest interface{}) (error) {
+
+	value := reflect.ValueOf(dest)
+
+	if(value.Kind() != reflect.Ptr || value.IsNil() || value.Elem().Kind() != reflect.Struct) {
+		return errors.New("Unable to scan row: destination is not a pointer to a struct")
+	}
+
+	columns, err := this.columnFields(rows, value.Elem().Type())
+	if(err != nil) {
+		return err
+	}
+	return scanInto(rows, value.Elem(), columns)
+}
+
+/*
+	ScanAll scans every remaining row of [rows] into the slice pointed to by [dest], then closes [rows].
+	The slice may hold structs, or pointers to structs; each row is appended to it.
+*/
+func (this RowScanner) ScanAll(rows *sql.Rows, dest interface{}) (error) {
+
+	var slice reflect.Value
+	var elementType reflect.Type
+	var structType reflect.Type
+	var element reflect.Value
+
+	defer rows.Close()
+
+	slice = reflect.ValueOf(dest)
+
+	if(slice.Kind() != reflect.Ptr || slice.IsNil() || slice.Elem().Kind() != reflect.Slice) {
+		return errors.New("Unable to scan rows: destination is not a pointer to a slice")
+	}
+
+	slice = slice.Elem()
+	elementType = slice.Type().Elem()
+	structType = elementType
+
+	if(structType.Kind() == reflect.Ptr) {
+		structType = structType.Elem()
+	}
+
+	if(structType.Kind() != reflect.Struct) {
+		return errors.New("Unable to scan rows: destination is not a slice of structs")
+	}
+
+	columns, err := this.columnFields(rows, structType)
+	if(err != nil) {
+		return err
+	}
+
+	for rows.Next() {
+
+		element = reflect.New(structType)
+
+		err = scanInto(rows, element.Elem(), columns)
+		if(err != nil) {
+			return err
+		}
+
+		if(elementType.Kind() != reflect.Ptr) {
+			element = element.Elem()
+		}
+		slice.Set(reflect.Append(slice, element))
+	}
+
+	return rows.Err()
+}
+
+/*
+	columnFields returns, for each column of [rows], the field index path of [structType] it should be scanned into,
+	or nil if the column should be thrown away.
+*/
+func (this RowScanner) columnFields(rows *sql.Rows, structType reflect.Type) ([][]int, error) {
+
+	var ret [][]int
+	var exact map[string][]int
+	var folded map[string][]int
+	var index []int
+	var found bool
+	var unmatched []string
+
+	columns, err := rows.Columns()
+	if(err != nil) {
+		return nil, err
+	}
+
+	fields := structFields(structType, columnTags)
+	exact = make(map[string][]int, len(fields))
+	folded = make(map[string][]int, len(fields))
+
+	for _, field := range fields {
+
+		exact[field.name] = field.index
+
+		_, found = folded[strings.ToLower(field.name)]
+		if(!found) {
+			folded[strings.ToLower(field.name)] = field.index
+		}
+	}
+
+	ret = make([][]int, len(columns))
+
+	for columnIndex, column := range columns {
+
+		index, found = exact[column]
+		if(!found) {
+			index, found = folded[strings.ToLower(column)]
+		}
+
+		if(!found) {
+			unmatched = append(unmatched, column)
+		}
+		ret[columnIndex] = index
+	}
+
+	if(len(unmatched) > 0 && this.UnmatchedColumns == UnmatchedColumnsFail) {
+		return nil, errors.New("Unable to scan rows: no fields match columns: " + strings.Join(unmatched, ", "))
+	}
+	return ret, nil
+}
+
+/*
+	scanInto scans the current row of [rows] into the fields of [structValue] given by [columns].
+*/
+func scanInto(rows *sql.Rows, structValue reflect.Value, columns [][]int) (error) {
+
+	var discard interface{}
+
+	destinations := make([]interface{}, len(columns))
+
+	for columnIndex, index := range columns {
+
+		if(index == nil) {
+			destinations[columnIndex] = &discard
+			continue
+		}
+		destinations[columnIndex] = fieldByIndex(structValue, index).Addr().Interface()
+	}
+
+	return rows.Scan(destinations...)
+}
@@ -0,0 +1,105 @@
+package namedParameterQuery
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"testing"
+)
+
+type scanTestAudit struct {
+	Created string `sqlColumnName:"created_at"`
+}
+
+type scanTestUser struct {
+	scanTestAudit
+	ID int64 `sqlParameterName:"id"`
+	Name string
+	Email *string
+	Nickname sql.NullString
+	hidden string
+}
+
+func TestScanAll(test *testing.T) {
+
+	var users []scanTestUser
+
+	connection, _ := openFakeDB(
+		[]string { "id", "name", "email", "nickname", "created_at" },
+		[]driver.Value { int64(1), "alice", "alice@example.com", "al", "2020-01-01" },
+		[]driver.Value { int64(2), "bob", nil, nil, "2021-01-01" },
+	)
+	defer connection.Close()
+
+	rows, err := connection.Query("SELECT * FROM users")
+	if(err == nil) {
+		err = ScanAll(rows, &users)
+	}
+
+	if(err != nil) {
+		test.Log("Unable to scan rows: ", err)
+		test.FailNow()
+	}
+
+	if(len(users) != 2) {
+		test.Log("Expected two rows to be scanned, got: ", len(users))
+		test.FailNow()
+	}
+
+	if(users[0].ID != 1 || users[0].Name != "alice" || users[0].Email == nil || *users[0].Email != "alice@example.com" ||
+		users[0].Nickname.String != "al" || users[0].Created != "2020-01-01") {
+		test.Log("First row was not scanned correctly: ", users[0])
+		test.Fail()
+	}
+
+	if(users[1].ID != 2 || users[1].Email != nil || users[1].Nickname.Valid || users[1].Created != "2021-01-01") {
+		test.Log("NULL columns were not scanned correctly: ", users[1])
+		test.Fail()
+	}
+}
+
+func TestScanRow(test *testing.T) {
+
+	var user *scanTestUser
+	var pointers []*scanTestUser
+
+	connection, _ := openFakeDB([]string { "id", "name", "extra" }, []driver.Value { int64(7), "carol", "ignored" })
+	defer connection.Close()
+
+	rows, err := connection.Query("SELECT * FROM users")
+	if(err != nil) {
+		test.Log("Unable to query: ", err)
+		test.FailNow()
+	}
+
+	rows.Next()
+	user = new(scanTestUser)
+
+	err = ScanRow(rows, user)
+	if(err == nil) {
+		test.Log("Expected an unmatched column to fail")
+		test.Fail()
+	}
+
+	err = RowScanner { UnmatchedColumns: UnmatchedColumnsIgnore }.ScanRow(rows, user)
+	if(err != nil || user.ID != 7 || user.Name != "carol") {
+		test.Log("Expected an unmatched column to be ignored: ", err, user)
+		test.Fail()
+	}
+	rows.Close()
+
+	rows, err = connection.Query("SELECT * FROM users")
+	if(err == nil) {
+		err = RowScanner { UnmatchedColumns: UnmatchedColumnsIgnore }.ScanAll(rows, &pointers)
+	}
+
+	if(err != nil || len(pointers) != 1 || pointers[0].Name != "carol") {
+		test.Log("Expected rows to be scanned into a slice of pointers: ", err)
+		test.Fail()
+	}
+
+	err = ScanRow(rows, *user)
+	if(err == nil) {
+		test.Log("Expected a non-pointer destination to fail")
+		test.Fail()
+	}
+}
@@ -0,0 +1,224 @@
+package namedParameterQuery
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sort"
+	"strings"
+)
+
+/*
+	NamedStmt is a prepared statement whose query uses named parameters.
+	The query is parsed once, when prepared, and each execution only binds values to it.
+
+	As with *sql.Stmt, a NamedStmt is safe to use from many goroutines at once.
+*/
+type NamedStmt struct {
+
+	// The prepared positional query.
+	stmt *sql.Stmt
+
+	// The parsed query, which maps named parameters to positions in [stmt].
+	template *QueryTemplate
+}
+
+/*
+	namedPreparer is implemented by *sql.DB, *sql.Tx and *sql.Conn; anything which can prepare a statement.
+*/
+type namedPreparer interface {
+	PrepareContext(ctx context.Context, query string) (*sql.Stmt, error)
+}
+
+/*
+	PrepareNamed parses the given [queryText] and prepares it as a statement, which can then be run many times
+	with different parameter values. A malformed query is reported as a *ParseError.
+*/
+func (this *NamedDB) PrepareNamed(queryText string) (*NamedStmt, error) {
+	return prepareNamed(context.Background(), this.DB, this.options, queryText)
+}
+
+// PrepareNamedContext is like PrepareNamed, but uses the given [ctx].
+func (this *NamedDB) PrepareNamedContext(ctx context.Context, queryText string) (*NamedStmt, error) {
+	return prepareNamed(ctx, this.DB, this.options, queryText)
+}
+
+// PrepareNamed prepares the given [queryText] within this transaction. See NamedDB.PrepareNamed.
+func (this *NamedTx) PrepareNamed(queryText string) (*NamedStmt, error) {
+	return prepareNamed(context.Background(), this.Tx, this.options, queryText)
+}
+
+// PrepareNamedContext is like PrepareNamed, but uses the given [ctx].
+func (this *NamedTx) PrepareNamedContext(ctx context.Context, queryText string) (*NamedStmt, error) {
+	return prepareNamed(ctx, this.Tx, this.options, queryText)
+}
+
+// PrepareNamed prepares the given [queryText] on this connection. See NamedDB.PrepareNamed.
+func (this *NamedConn) PrepareNamed(queryText string) (*NamedStmt, error) {
+	return prepareNamed(context.Background(), this.Conn, this.options, queryText)
+}
+
+// PrepareNamedContext is like PrepareNamed, but uses the given [ctx].
+func (this *NamedConn) PrepareNamedContext(ctx context.Context, queryText string) (*NamedStmt, error) {
+	return prepareNamed(ctx, this.Conn, this.options, queryText)
+}
+
+/*
+	StmtNamed returns a transaction-specific version of the given [stmt], in the same way as sql.Tx's Stmt.
+	The returned statement shares the parsed query of the given one.
+*/
+func (this *NamedTx) StmtNamed(stmt *NamedStmt) (*NamedStmt) {
+	return this.StmtNamedContext(context.Background(), stmt)
+}
+
+// StmtNamedContext is like StmtNamed, but uses the given [ctx].
+func (this *NamedTx) StmtNamedContext(ctx context.Context, stmt *NamedStmt) (*NamedStmt) {
+
+	return &NamedStmt {
+		stmt: this.Tx.StmtContext(ctx, stmt.stmt),
+		template: stmt.template,
+	}
+}
+
+func prepareNamed(ctx context.Context, preparer namedPreparer, options QueryOptions, queryText string) (*NamedStmt, error) {
+
+	template, err := DefaultTemplateCache.Parse(queryText, options)
+	if(err != nil) {
+		return nil, err
+	}
+
+	err = checkPreparable(template)
+	if(err != nil) {
+		return nil, err
+	}
+
+	stmt, err := preparer.PrepareContext(ctx, template.GetParsedQuery())
+	if(err != nil) {
+		return nil, err
+	}
+
+	return &NamedStmt { stmt: stmt, template: template }, nil
+}
+
+/*
+	checkPreparable returns an error if the given [template] has identifier parameters, which must be written
+	into the query itself, or slots, which must be filled first; neither can be part of a prepared statement.
+*/
+func checkPreparable(template *QueryTemplate) (error) {
+
+	if(len(template.identifierNames) > 0) {
+		return errors.New("Unable to prepare statement: identifier parameters cannot be prepared: " + strings.Join(template.identifierNames, ", "))
+	}
+	if(len(template.slotNames) > 0) {
+		return errors.New("Unable to prepare statement: slots must be composed before preparing: " + strings.Join(template.slotNames, ", "))
+	}
+	return nil
+}
+
+/*
+	Stmt returns the underlying prepared statement, which takes positional parameters.
+*/
+func (this *NamedStmt) Stmt() (*sql.Stmt) {
+	return this.stmt
+}
+
+/*
+	Template returns the parsed query of this statement.
+*/
+func (this *NamedStmt) Template() (*QueryTemplate) {
+	return this.template
+}
+
+/*
+	Close closes the underlying prepared statement.
+*/
+func (this *NamedStmt) Close() (error) {
+	return this.stmt.Close()
+}
+
+/*
+	Exec runs this statement without returning any rows, taking the values of its named parameters
+	from [parameters], which may be a map or a struct. Every named parameter must be given a value.
+
+	Since the statement's positional query is fixed when it's prepared, parameters cannot be given slice values,
+	and optional fragments cannot be left out.
+*/
+func (this *NamedStmt) Exec(parameters interface{}) (sql.Result, error) {
+	return this.ExecContext(context.Background(), parameters)
+}
+
+// ExecContext is like Exec, but uses the given [ctx].
+func (this *NamedStmt) ExecContext(ctx context.Context, parameters interface{}) (sql.Result, error) {
+
+	args, err := this.bind(parameters)
+	if(err != nil) {
+		return nil, err
+	}
+	return this.stmt.ExecContext(ctx, args...)
+}
+
+/*
+	Query runs this statement, taking the values of its named parameters from [parameters] as Exec does.
+*/
+func (this *NamedStmt) Query(parameters interface{}) (*sql.Rows, error) {
+	return this.QueryContext(context.Background(), parameters)
+}
+
+// QueryContext is like Query, but uses the given [ctx].
+func (this *NamedStmt) QueryContext(ctx context.Context, parameters interface{}) (*sql.Rows, error) {
+
+	args, err := this.bind(parameters)
+	if(err != nil) {
+		return nil, err
+	}
+	return this.stmt.QueryContext(ctx, args...)
+}
+
+/*
+	QueryRow runs this statement, which is expected to return at most one row,
+	taking the values of its named parameters from [parameters] as Exec does.
+	Any error is deferred until the row is scanned.
+*/
+func (this *NamedStmt) QueryRow(parameters interface{}) (*NamedRow) {
+	return this.QueryRowContext(context.Background(), parameters)
+}
+
+// QueryRowContext is like QueryRow, but uses the given [ctx].
+func (this *NamedStmt) QueryRowContext(ctx context.Context, parameters interface{}) (*NamedRow) {
+
+	args, err := this.bind(parameters)
+	if(err != nil) {
+		return &NamedRow { err: err }
+	}
+	return &NamedRow { row: this.stmt.QueryRowContext(ctx, args...) }
+}
+
+/*
+	bind returns the positional arguments for the given [parameters].
+*/
+func (this *NamedStmt) bind(parameters interface{}) ([]interface{}, error) {
+
+	var expanded []string
+
+	binding, err := bindTemplate(this.template, parameters)
+	if(err != nil) {
+		return nil, err
+	}
+
+	// the prepared query has exactly one placeholder per parameter.
+	if(len(binding.expanded) > 0) {
+
+		for name := range binding.expanded {
+			expanded = append(expanded, name)
+		}
+		sort.Strings(expanded)
+
+		return nil, errors.New("Unable to execute prepared statement: slice values cannot be expanded for parameters: " + strings.Join(expanded, ", "))
+	}
+
+	if(binding.dropped) {
+		return nil, errors.New("Unable to execute prepared statement: optional fragments cannot be left out of a prepared statement")
+	}
+
+	return binding.GetParsedParameters(), nil
+}
@@ -0,0 +1,140 @@
+package namedParameterQuery
+
+import (
+	"context"
+	"database/sql/driver"
+	"testing"
+)
+
+func TestNamedStmt(test *testing.T) {
+
+	var name string
+
+	connection, fake := openFakeDB([]string { "name" }, []driver.Value { "bob" })
+	db := NewNamedDB(connection, QueryOptions { Dialect: SQLServerDialect })
+	defer db.Close()
+
+	stmt, err := db.PrepareNamed("SELECT name FROM table WHERE col1 = :foo AND col2 = :bar AND col3 = :foo")
+	if(err != nil) {
+		test.Log("Unable to prepare statement: ", err)
+		test.FailNow()
+	}
+	defer stmt.Close()
+
+	_, err = stmt.Exec(map[string]interface{} { "foo": "a", "bar": 1 })
+	verifyFakeStatement("FirstExec", test, err, fake, "SELECT name FROM table WHERE col1 = @p1 AND col2 = @p2 AND col3 = @p1", "a", int64(1))
+
+	_, err = stmt.Exec(databaseTestParameters { Foo: "b", Bar: 2 })
+	verifyFakeStatement("SecondExec", test, err, fake, "SELECT name FROM table WHERE col1 = @p1 AND col2 = @p2 AND col3 = @p1", "b", int64(2))
+
+	err = stmt.QueryRow(databaseTestParameters { Foo: "c", Bar: 3 }).Scan(&name)
+	verifyFakeStatement("QueryRow", test, err, fake, "SELECT name FROM table WHERE col1 = @p1 AND col2 = @p2 AND col3 = @p1", "c", int64(3))
+
+	if(name != "bob") {
+		test.Log("Expected the row to be scanned, got: ", name)
+		test.Fail()
+	}
+
+	if(fake.prepared != 1) {
+		test.Log("Expected the statement to be prepared exactly once, was prepared ", fake.prepared, " times")
+		test.Fail()
+	}
+}
+
+func TestNamedStmtErrors(test *testing.T) {
+
+	connection, fake := openFakeDB(nil)
+	db := NewNamedDB(connection, QueryOptions{})
+	defer db.Close()
+
+	_, err := db.PrepareNamed("SELECT * FROM table WHERE col1 = 'oops")
+	if(err == nil) {
+		test.Log("Expected a malformed query to fail to prepare")
+		test.Fail()
+	}
+
+	stmt, err := db.PrepareNamed("SELECT * FROM table WHERE id IN (:ids)")
+	if(err != nil) {
+		test.Log("Unable to prepare statement: ", err)
+		test.FailNow()
+	}
+	defer stmt.Close()
+
+	_, err = stmt.Query(map[string]interface{} { "ids": []int { 1, 2 } })
+	if(err == nil) {
+		test.Log("Expected a slice value to be rejected by a prepared statement")
+		test.Fail()
+	}
+
+	_, err = stmt.Exec(map[string]interface{}{})
+	if(err == nil) {
+		test.Log("Expected an unbound parameter to be rejected by a prepared statement")
+		test.Fail()
+	}
+
+	_, err = db.PrepareNamed("SELECT * FROM table ORDER BY :!column")
+	if(err == nil) {
+		test.Log("Expected a query with identifier parameters to fail to prepare")
+		test.Fail()
+	}
+
+	slotted := NewNamedDB(connection, QueryOptions { Slots: true })
+
+	_, err = slotted.PrepareNamed("SELECT * FROM table WHERE {{filter}}")
+	if(err == nil) {
+		test.Log("Expected a query with an unfilled slot to fail to prepare")
+		test.Fail()
+	}
+
+	optional := NewNamedDB(connection, QueryOptions { OptionalFragments: true })
+
+	stmt, err = optional.PrepareNamed("SELECT * FROM table WHERE 1 = 1 /*[ AND col1 = :foo ]*/")
+	if(err != nil) {
+		test.Log("Unable to prepare statement: ", err)
+		test.FailNow()
+	}
+	defer stmt.Close()
+
+	_, err = stmt.Exec(nil)
+	if(err == nil) {
+		test.Log("Expected a left out fragment to be rejected by a prepared statement")
+		test.Fail()
+	}
+
+	if(len(fake.statements) != 0) {
+		test.Log("Expected no failed statement to reach the driver")
+		test.Fail()
+	}
+}
+
+func TestNamedStmtInTransaction(test *testing.T) {
+
+	ctx := context.Background()
+	connection, fake := openFakeDB(nil)
+	db := NewNamedDB(connection, QueryOptions{})
+	defer db.Close()
+
+	stmt, err := db.PrepareNamed("DELETE FROM table WHERE col1 = :foo")
+	if(err != nil) {
+		test.Log("Unable to prepare statement: ", err)
+		test.FailNow()
+	}
+	defer stmt.Close()
+
+	tx, err := db.BeginNamed(ctx, nil)
+	if(err != nil) {
+		test.Log("Unable to begin transaction: ", err)
+		test.FailNow()
+	}
+	defer tx.Rollback()
+
+	txStmt := tx.StmtNamed(stmt)
+
+	_, err = txStmt.Exec(map[string]interface{} { "foo": "d" })
+	verifyFakeStatement("TransactionStmt", test, err, fake, "DELETE FROM table WHERE col1 = ?", "d")
+
+	if(txStmt.Template() != stmt.Template()) {
+		test.Log("Expected the transaction's statement to share the parsed query")
+		test.Fail()
+	}
+}
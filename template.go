@@ -0,0 +1,357 @@
+package namedParameterQuery
+
+import (
+	"bytes"
+)
+
+/*
+	QueryTemplate is a parsed named parameter query. It holds where each named parameter appears,
+	and the positional query built from it, but no parameter values.
+
+	A QueryTemplate never changes once parsed, so it is safe to share between goroutines;
+	e.g., parse it once at package init, then call Bind to set values for each execution.
+*/
+type QueryTemplate struct {
+
+	// A map of parameter names (as mapped by the options' NameMapper) as keys,
+	// with value as a slice of positional indices which match that parameter.
+	positions map[string][]int
+
+	// The (mapped) names of every parameter, in the order they first appear in the query.
+	names []string
+
+	// The number of optional fragments in the query, and the names of parameters which only appear inside them.
+	fragments int
+	optionalNames map[string]bool
+
+	// The (mapped) names of every identifier parameter, in the order they first appear,
+	// and those which only appear inside optional fragments.
+	identifierNames []string
+	optionalIdentifiers map[string]bool
+
+	// The names of every slot which hasn't had a query composed into it, in the order they first appear.
+	slotNames []string
+
+	// The number of positional parameters in revisedQuery.
+	parameterCount int
+
+	// The query containing named parameters, as passed in when parsed
+	originalQuery string
+
+	// The query containing positional parameters, as generated by setQuery
+	revisedQuery string
+
+	// Determines how the query is parsed, and how positional parameters are written into revisedQuery
+	options QueryOptions
+
+	// The query, split into plain text and parameters, in order.
+	segments []segment
+}
+
+/*
+	Represents what a segment of a template holds.
+*/
+type segmentKind int
+
+const (
+
+	// Plain SQL, including strings and comments, which is copied as-is.
+	textSegment segmentKind = iota
+
+	// A named parameter.
+	parameterSegment
+
+	// The start or end of an optional fragment. Their text is never written into the parsed query.
+	optionalStartSegment
+	optionalEndSegment
+
+	// A named identifier parameter, which is written into the query itself, rather than passed as a parameter.
+	identifierSegment
+
+	// A slot into which another query can be composed. Until then, its text is written as it is. See Compose.
+	slotSegment
+)
+
+/*
+	A segment is a single piece of a parsed query; either plain text, or a single occurrence of a named parameter.
+*/
+type segment struct {
+	kind segmentKind
+
+	// The original text of this segment.
+	text string
+
+	// For parameter segments, the name of the parameter, as mapped by the template's NameMapper.
+	name string
+
+	// The byte offset of this segment in the original query.
+	offset int
+}
+
+/*
+	ParseQueryTemplate parses the given [queryText] according to the given [options],
+	in the same way as ParseNamedParameterQueryWithOptions. A malformed query is reported as a *ParseError.
+*/
+func ParseQueryTemplate(queryText string, options QueryOptions) (*QueryTemplate, error) {
+
+	ret, err := parseQueryTemplate(queryText, options)
+	if(err != nil) {
+		return nil, err
+	}
+	return ret, nil
+}
+
+/*
+	MustParseQueryTemplate is like ParseQueryTemplate, but panics if the query is malformed.
+	It is intended for queries defined as package-level variables.
+*/
+func MustParseQueryTemplate(queryText string, options QueryOptions) (*QueryTemplate) {
+
+	ret, err := ParseQueryTemplate(queryText, options)
+	if(err != nil) {
+		panic(err)
+	}
+	return ret
+}
+
+/*
+	parseQueryTemplate parses the given [queryText] using the given [options].
+	The template is always returned, even if the query text was malformed.
+*/
+func parseQueryTemplate(queryText string, options QueryOptions) (*QueryTemplate, error) {
+
+	var ret *QueryTemplate
+	var err error
+
+	ret = new(QueryTemplate)
+	ret.options = options.withDefaults()
+	err = ret.setQuery(queryText)
+
+	return ret, err
+}
+
+/*
+	setQuery parses out all named parameters into segments, then builds the template from them.
+	If the query is malformed, the template is still built, but the first problem found is returned.
+*/
+func (this *QueryTemplate) setQuery(queryText string) (error) {
+
+	var last int
+
+	this.originalQuery = queryText
+	this.segments = make([]segment, 0, 8)
+
+	tokens, err := lexQuery(queryText, this.options)
+
+	// only tokens which are actual SQL can hold parameters; strings, identifiers and comments are copied verbatim.
+	for _, token := range tokens {
+
+		switch token.kind {
+		case parameterToken:
+			this.segments = append(this.segments, segment {
+				kind: parameterSegment,
+				text: token.text,
+				name: this.options.Names(token.value),
+				offset: token.offset,
+			})
+			continue
+
+		case optionalStartToken, optionalEndToken:
+			this.segments = append(this.segments, segment {
+				kind: fragmentSegmentKinds[token.kind],
+				text: token.text,
+				offset: token.offset,
+			})
+			continue
+
+		case identifierParameterToken:
+			this.segments = append(this.segments, segment {
+				kind: identifierSegment,
+				text: token.text,
+				name: this.options.Names(token.value),
+				offset: token.offset,
+			})
+			continue
+
+		case slotToken:
+			this.segments = append(this.segments, segment {
+				kind: slotSegment,
+				text: token.text,
+				name: token.value,
+				offset: token.offset,
+			})
+			continue
+		}
+
+		// merge runs of plain text into one segment.
+		last = len(this.segments) - 1
+		if(last >= 0 && this.segments[last].kind == textSegment) {
+			this.segments[last].text += token.text
+			continue
+		}
+
+		this.segments = append(this.segments, segment {
+			kind: textSegment,
+			text: token.text,
+			offset: token.offset,
+		})
+	}
+
+	this.build()
+	return err
+}
+
+var fragmentSegmentKinds = map[tokenKind]segmentKind {
+	optionalStartToken: optionalStartSegment,
+	optionalEndToken: optionalEndSegment,
+}
+
+/*
+	build stores the locations of all named parameters in this template's segments, and
+	builds a "revised" query which uses positional parameters.
+*/
+func (this *QueryTemplate) build() {
+
+	var revisedBuilder bytes.Buffer
+	var dialect Dialect
+	var position []int
+	var positionIndex int
+	var depth int
+	var required map[string]bool
+	var requiredIdentifiers map[string]bool
+
+	dialect = this.options.Dialect
+	positionIndex = 0
+
+	// TODO: I don't like using a map for such a small amount of elements.
+	// If this becomes a bottleneck for anyone, the first thing to do would
+	// be to make a slice and search routine for parameter positions.
+	this.positions = make(map[string][]int, 8)
+	this.names = nil
+	this.fragments = 0
+	this.optionalNames = nil
+	this.identifierNames = nil
+	this.optionalIdentifiers = nil
+	this.slotNames = nil
+	required = make(map[string]bool, 8)
+	requiredIdentifiers = make(map[string]bool)
+
+	// the revised query includes every optional fragment; bindings which leave any out render their own.
+	for _, segment := range this.segments {
+
+		switch segment.kind {
+		case textSegment:
+			revisedBuilder.WriteString(segment.text)
+			continue
+
+		// a slot which hasn't been composed is kept as it is, which Validate will report.
+		case slotSegment:
+			revisedBuilder.WriteString(segment.text)
+			this.slotNames = appendUnique(this.slotNames, segment.name)
+			continue
+
+		case optionalStartSegment:
+			this.fragments++
+			depth++
+			continue
+
+		case optionalEndSegment:
+			depth--
+			continue
+
+		// identifiers can only be written by a binding, so the revised query keeps them as they are.
+		case identifierSegment:
+			revisedBuilder.WriteString(segment.text)
+
+			if(!this.hasIdentifier(segment.name)) {
+				this.identifierNames = append(this.identifierNames, segment.name)
+			}
+			if(depth == 0) {
+				requiredIdentifiers[segment.name] = true
+			}
+			continue
+		}
+
+		if(depth == 0) {
+			required[segment.name] = true
+		}
+
+		// add to positions, unless this dialect can refer back to an earlier occurrence
+		position = this.positions[segment.name]
+
+		if(len(position) == 0) {
+			this.names = append(this.names, segment.name)
+		}
+
+		if(len(position) > 0 && dialect.ReusesPlaceholders()) {
+			revisedBuilder.WriteString(dialect.Placeholder(position[0]))
+		} else {
+			this.positions[segment.name] = append(position, positionIndex)
+			revisedBuilder.WriteString(dialect.Placeholder(positionIndex))
+			positionIndex++
+		}
+	}
+
+	this.revisedQuery = revisedBuilder.String()
+	this.parameterCount = positionIndex
+
+	if(this.fragments > 0) {
+
+		this.optionalNames = make(map[string]bool)
+
+		for _, name := range this.names {
+			if(!required[name]) {
+				this.optionalNames[name] = true
+			}
+		}
+
+		this.optionalIdentifiers = make(map[string]bool)
+
+		for _, name := range this.identifierNames {
+			if(!requiredIdentifiers[name]) {
+				this.optionalIdentifiers[name] = true
+			}
+		}
+	}
+}
+
+/*
+	hasIdentifier returns true if this template has an identifier parameter with the given (mapped) [name].
+*/
+func (this *QueryTemplate) hasIdentifier(name string) (bool) {
+
+	for _, existing := range this.identifierNames {
+		if(existing == name) {
+			return true
+		}
+	}
+	return false
+}
+
+/*
+	GetParsedQuery returns a version of the original query text
+	whose named parameters have been replaced by positional parameters.
+*/
+func (this *QueryTemplate) GetParsedQuery() (string) {
+	return this.revisedQuery
+}
+
+/*
+	GetOriginalQuery returns the query text containing named parameters, exactly as it was parsed.
+*/
+func (this *QueryTemplate) GetOriginalQuery() (string) {
+	return this.originalQuery
+}
+
+/*
+	Bind returns a new Binding of this template, with no parameter values set.
+	Each Binding is independent, so many can be used at once from different goroutines.
+*/
+func (this *QueryTemplate) Bind() (*Binding) {
+
+	var ret *Binding
+
+	ret = new(Binding)
+	ret.setTemplate(this)
+	return ret
+}